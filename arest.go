@@ -12,6 +12,12 @@ type Arest interface {
 	// DigitalRead permit to read level from pin
 	DigitalRead(pin int) (level Level, err error)
 
+	// AnalogRead permit to read analog value from pin
+	AnalogRead(pin int) (value int, err error)
+
+	// AnalogWrite permit to set analog value (PWM) on pin
+	AnalogWrite(pin int, value int) (err error)
+
 	// ReadValue permit to read user variable
 	ReadValue(name string) (value interface{}, err error)
 
@@ -0,0 +1,156 @@
+package cache
+
+import (
+	"sync"
+	"time"
+
+	"github.com/disaster37/go-arest"
+	"github.com/pkg/errors"
+)
+
+// Client implement arest interface on top of other client
+// It serve ReadValue and ReadValues from the last variables fetch while it's not expired
+// and coalesce concurrent fetch in one request
+type Client struct {
+	client    arest.Arest
+	ttl       time.Duration
+	mutex     sync.Mutex
+	values    map[string]interface{}
+	fetchedAt time.Time
+	inflight  *fetch
+}
+
+// fetch is a variables fetch in progress
+type fetch struct {
+	done   chan struct{}
+	values map[string]interface{}
+	err    error
+}
+
+// NewClient permit to initialize new cache client Object
+func NewClient(client arest.Arest, ttl time.Duration) arest.Arest {
+	return &Client{
+		client: client,
+		ttl:    ttl,
+	}
+}
+
+// Client permit to get the decorated client
+func (c *Client) Client() arest.Arest {
+	return c.client
+}
+
+// Invalidate permit to drop the cached variables
+// The next read will fetch variables from board, even if fetch is already in progress
+func (c *Client) Invalidate() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.values = nil
+	c.inflight = nil
+}
+
+// SetPinMode permit to set pin mode
+func (c *Client) SetPinMode(pin int, mode arest.Mode) (err error) {
+	return c.client.SetPinMode(pin, mode)
+}
+
+// DigitalWrite permit to set level on pin
+func (c *Client) DigitalWrite(pin int, level arest.Level) (err error) {
+	return c.client.DigitalWrite(pin, level)
+}
+
+// DigitalRead permit to read level from pin
+func (c *Client) DigitalRead(pin int) (level arest.Level, err error) {
+	return c.client.DigitalRead(pin)
+}
+
+// AnalogRead permit to read analog value from pin
+func (c *Client) AnalogRead(pin int) (value int, err error) {
+	return c.client.AnalogRead(pin)
+}
+
+// AnalogWrite permit to set analog value (PWM) on pin
+func (c *Client) AnalogWrite(pin int, value int) (err error) {
+	return c.client.AnalogWrite(pin, value)
+}
+
+// ReadValue permit to read user variable from cache
+func (c *Client) ReadValue(name string) (value interface{}, err error) {
+	values, err := c.read()
+	if err != nil {
+		return nil, err
+	}
+
+	value, ok := values[name]
+	if !ok {
+		return nil, errors.Errorf("Variable %s not found", name)
+	}
+
+	return value, nil
+}
+
+// ReadValues permit to read all user variables from cache
+func (c *Client) ReadValues() (values map[string]interface{}, err error) {
+	cached, err := c.read()
+	if err != nil {
+		return nil, err
+	}
+
+	// Return a copy, so the caller can't alter the cache
+	values = make(map[string]interface{}, len(cached))
+	for name, value := range cached {
+		values[name] = value
+	}
+
+	return values, nil
+}
+
+// CallFunction permit to call user function
+// It invalidate the cache because user function usually change variables
+func (c *Client) CallFunction(name string, param string) (resp int, err error) {
+	resp, err = c.client.CallFunction(name, param)
+	c.Invalidate()
+
+	return resp, err
+}
+
+// read return the cached variables or fetch them.
+// When fetch is already in progress, it wait it instead to send new request
+func (c *Client) read() (map[string]interface{}, error) {
+	c.mutex.Lock()
+	if c.values != nil && time.Since(c.fetchedAt) < c.ttl {
+		values := c.values
+		c.mutex.Unlock()
+		return values, nil
+	}
+
+	if f := c.inflight; f != nil {
+		c.mutex.Unlock()
+		<-f.done
+		return f.values, f.err
+	}
+
+	f := &fetch{
+		done: make(chan struct{}),
+	}
+	c.inflight = f
+	c.mutex.Unlock()
+
+	arest.Debug("Fetch variables")
+	f.values, f.err = c.client.ReadValues()
+
+	c.mutex.Lock()
+	// Keep the result only if cache was not invalidated during fetch
+	if c.inflight == f {
+		c.inflight = nil
+		if f.err == nil {
+			c.values = f.values
+			c.fetchedAt = time.Now()
+		}
+	}
+	c.mutex.Unlock()
+	close(f.done)
+
+	return f.values, f.err
+}
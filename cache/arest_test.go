@@ -0,0 +1,101 @@
+package cache
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/disaster37/go-arest/rest"
+	"github.com/jarcoal/httpmock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	prefixed "github.com/x-cray/logrus-prefixed-formatter"
+)
+
+func TestCache(t *testing.T) {
+	// Init logger
+	logrus.SetFormatter(new(prefixed.TextFormatter))
+	logrus.SetLevel(logrus.DebugLevel)
+
+	var nbCall int32
+	httpmock.RegisterResponder("GET", "http://localhost/", func(req *http.Request) (*http.Response, error) {
+		atomic.AddInt32(&nbCall, 1)
+		time.Sleep(50 * time.Millisecond)
+		return httpmock.NewJsonResponse(200, map[string]interface{}{
+			"variables": map[string]interface{}{
+				"temperature": 21.5,
+				"isRebooted":  false,
+			},
+		})
+	})
+	httpmock.RegisterResponder("POST", "http://localhost/acknoledgeRebooted?params=test", httpmock.NewJsonResponderOrPanic(200, map[string]interface{}{
+		"return_value": 1,
+	}))
+
+	client := NewClient(rest.MockRestClient(), 500*time.Millisecond)
+
+	// Read value from root fetch
+	value, err := client.ReadValue("temperature")
+	assert.NoError(t, err)
+	assert.Equal(t, 21.5, value)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&nbCall))
+
+	// Read values from cache
+	values, err := client.ReadValues()
+	assert.NoError(t, err)
+	assert.Equal(t, false, values["isRebooted"])
+	assert.Equal(t, int32(1), atomic.LoadInt32(&nbCall))
+
+	// Caller can't alter the cache
+	values["temperature"] = 0
+	value, err = client.ReadValue("temperature")
+	assert.NoError(t, err)
+	assert.Equal(t, 21.5, value)
+
+	// Bad variable
+	_, err = client.ReadValue("bad")
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&nbCall))
+
+	// Explicit invalidation
+	client.(*Client).Invalidate()
+	_, err = client.ReadValue("temperature")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&nbCall))
+
+	// Call function invalidate cache
+	_, err = client.CallFunction("acknoledgeRebooted", "test")
+	assert.NoError(t, err)
+	_, err = client.ReadValue("temperature")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&nbCall))
+
+	// Expired
+	time.Sleep(600 * time.Millisecond)
+	_, err = client.ReadValue("temperature")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(4), atomic.LoadInt32(&nbCall))
+
+	// Concurrent reads are coalesced
+	client.(*Client).Invalidate()
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := client.ReadValue("temperature")
+			assert.NoError(t, err)
+			assert.Equal(t, 21.5, value)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(5), atomic.LoadInt32(&nbCall))
+
+	// Error is not cached
+	httpmock.Reset()
+	client.(*Client).Invalidate()
+	_, err = client.ReadValues()
+	assert.Error(t, err)
+}
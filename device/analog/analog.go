@@ -0,0 +1,170 @@
+package analog
+
+import (
+	"math"
+	"sync"
+
+	"github.com/disaster37/go-arest"
+	"github.com/pkg/errors"
+)
+
+// ErrOutOfRange is returned when value is outside the sensor range
+var ErrOutOfRange = errors.New("Value is out of range")
+
+// Sensor is the analog sensor interface
+type Sensor interface {
+
+	// Read read the pin and return the value in engineering unit.
+	// When value is outside the range or the calibration, it return the clamped value and ErrOutOfRange
+	Read() (value float64, err error)
+
+	// Value return the last value
+	Value() (value float64)
+
+	// Raw return the last ADC count
+	Raw() (raw int)
+
+	// Voltage return the last voltage
+	Voltage() (voltage float64)
+
+	// Reset forget the smoothed values
+	Reset()
+}
+
+// SensorImp is the default Sensor implementation
+// It's safe for concurrent use
+type SensorImp struct {
+	pin         int
+	client      arest.Arest
+	maxCount    int
+	reference   float64
+	calibration Calibration
+	filter      Filter
+	min         float64
+	max         float64
+	raw         int
+	voltage     float64
+	value       float64
+	mutex       sync.Mutex
+}
+
+// NewSensor return new analog sensor.
+// Resolution is the board ADC resolution in bits, and reference is the ADC reference voltage.
+// Without calibration, the value is the voltage
+func NewSensor(client arest.Arest, pin int, resolution int, reference float64) (Sensor, error) {
+	if resolution < 1 || resolution > 24 {
+		return nil, errors.Errorf("ADC resolution must be between 1 and 24 bits: %d", resolution)
+	}
+	if reference <= 0 {
+		return nil, errors.Errorf("Reference voltage must be positive: %f", reference)
+	}
+
+	return &SensorImp{
+		pin:         pin,
+		client:      client,
+		maxCount:    1<<uint(resolution) - 1,
+		reference:   reference,
+		calibration: NewLinearCalibration(1, 0),
+		min:         math.Inf(-1),
+		max:         math.Inf(1),
+	}, nil
+}
+
+// SetCalibration permit to convert voltage to engineering unit
+func (h *SensorImp) SetCalibration(calibration Calibration) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.calibration = calibration
+}
+
+// SetFilter permit to smooth the values.
+// Values outside the range or the calibration are not fed to the filter
+func (h *SensorImp) SetFilter(filter Filter) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.filter = filter
+}
+
+// SetRange permit to clamp the values between min and max
+func (h *SensorImp) SetRange(min float64, max float64) error {
+	if min > max {
+		return errors.Errorf("Min %f is greater than max %f", min, max)
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.min = min
+	h.max = max
+	return nil
+}
+
+// Read read the pin and return the value in engineering unit.
+// When value is outside the range or the calibration, it return the clamped value and ErrOutOfRange.
+// The clamped value is not smoothed, so it not skew the filter
+func (h *SensorImp) Read() (value float64, err error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	raw, err := h.client.AnalogRead(h.pin)
+	if err != nil {
+		return 0, err
+	}
+	voltage := float64(raw) / float64(h.maxCount) * h.reference
+
+	value, rangeErr := h.calibration.Convert(voltage)
+	if rangeErr != nil && errors.Cause(rangeErr) != ErrOutOfRange {
+		return 0, rangeErr
+	}
+
+	if value < h.min || value > h.max {
+		rangeErr = errors.Wrapf(ErrOutOfRange, "Value %f is outside [%f, %f]", value, h.min, h.max)
+		value = math.Max(h.min, math.Min(h.max, value))
+	}
+
+	if h.filter != nil && rangeErr == nil {
+		value = h.filter.Update(value)
+	}
+
+	h.raw = raw
+	h.voltage = voltage
+	h.value = value
+
+	return value, rangeErr
+}
+
+// Value return the last value
+func (h *SensorImp) Value() float64 {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return h.value
+}
+
+// Raw return the last ADC count
+func (h *SensorImp) Raw() int {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return h.raw
+}
+
+// Voltage return the last voltage
+func (h *SensorImp) Voltage() float64 {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return h.voltage
+}
+
+// Reset forget the smoothed values
+func (h *SensorImp) Reset() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.filter != nil {
+		h.filter.Reset()
+	}
+}
@@ -0,0 +1,100 @@
+package analog
+
+import (
+	"math"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/disaster37/go-arest/rest"
+	"github.com/jarcoal/httpmock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSensor(t *testing.T) {
+	client := rest.MockRestClient()
+	var rawValue int32
+	httpmock.RegisterResponder("GET", "http://localhost/analog/1", func(req *http.Request) (*http.Response, error) {
+		return httpmock.NewJsonResponse(200, map[string]interface{}{
+			"return_value": atomic.LoadInt32(&rawValue),
+		})
+	})
+
+	// Bad settings
+	_, err := NewSensor(client, 1, 0, 5)
+	assert.Error(t, err)
+	_, err = NewSensor(client, 1, 10, 0)
+	assert.Error(t, err)
+
+	// Without calibration, value is the voltage
+	sensor, err := NewSensor(client, 1, 10, 5)
+	assert.NoError(t, err)
+	atomic.StoreInt32(&rawValue, 1023)
+	value, err := sensor.Read()
+	assert.NoError(t, err)
+	assert.Equal(t, 5.0, value)
+	assert.Equal(t, 1023, sensor.Raw())
+	assert.Equal(t, 5.0, sensor.Voltage())
+
+	// Calibration, 0.5V - 4.5V is 0 - 100 psi
+	sensorImp := sensor.(*SensorImp)
+	sensorImp.SetCalibration(NewLinearCalibration(25, -12.5))
+	atomic.StoreInt32(&rawValue, 0)
+	assert.Error(t, sensorImp.SetRange(100, 0))
+	assert.NoError(t, sensorImp.SetRange(0, 100))
+
+	// Out of range is clamped
+	value, err = sensor.Read()
+	assert.Equal(t, ErrOutOfRange, errors.Cause(err))
+	assert.Equal(t, 0.0, value)
+	assert.Equal(t, 0.0, sensor.Value())
+
+	// Smoothing
+	sensorImp.SetFilter(NewMovingAverageFilter(2))
+	atomic.StoreInt32(&rawValue, 511)
+	value, err = sensor.Read()
+	assert.NoError(t, err)
+	assert.InDelta(t, 49.9, value, 0.1)
+	atomic.StoreInt32(&rawValue, 920)
+	value, err = sensor.Read()
+	assert.NoError(t, err)
+	assert.InDelta(t, 74.9, value, 0.1)
+
+	// Reset
+	sensor.Reset()
+	value, err = sensor.Read()
+	assert.NoError(t, err)
+	assert.InDelta(t, 99.9, value, 0.1)
+
+	// Out of range is not smoothed and not fed to the filter
+	sensor.Reset()
+	atomic.StoreInt32(&rawValue, 1023)
+	value, err = sensor.Read()
+	assert.Equal(t, ErrOutOfRange, errors.Cause(err))
+	assert.Equal(t, 100.0, value)
+	assert.Equal(t, 100.0, sensor.Value())
+	atomic.StoreInt32(&rawValue, 511)
+	value, err = sensor.Read()
+	assert.NoError(t, err)
+	assert.InDelta(t, 49.9, value, 0.1)
+
+	// Outside calibration table is clamped like outside range
+	assert.NoError(t, sensorImp.SetRange(math.Inf(-1), math.Inf(1)))
+	table, err := NewTableCalibration([]Point{{Voltage: 1, Value: 10}, {Voltage: 4, Value: 40}})
+	assert.NoError(t, err)
+	sensorImp.SetCalibration(table)
+	sensor.Reset()
+	atomic.StoreInt32(&rawValue, 1023)
+	value, err = sensor.Read()
+	assert.Equal(t, ErrOutOfRange, errors.Cause(err))
+	assert.Equal(t, 40.0, value)
+	atomic.StoreInt32(&rawValue, 0)
+	value, err = sensor.Read()
+	assert.Equal(t, ErrOutOfRange, errors.Cause(err))
+	assert.Equal(t, 10.0, value)
+	atomic.StoreInt32(&rawValue, 511)
+	value, err = sensor.Read()
+	assert.NoError(t, err)
+	assert.InDelta(t, 24.9, value, 0.1)
+}
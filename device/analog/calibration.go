@@ -0,0 +1,119 @@
+package analog
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// Calibration convert the voltage read on pin to engineering unit
+type Calibration interface {
+
+	// Convert return the value in engineering unit from voltage
+	// When voltage is outside the calibration, it return the clamped value and ErrOutOfRange
+	Convert(voltage float64) (value float64, err error)
+}
+
+// LinearCalibration is value = gain * voltage + offset
+type LinearCalibration struct {
+	gain   float64
+	offset float64
+}
+
+// PolynomialCalibration is value = c0 + c1 * voltage + c2 * voltage^2 + ...
+type PolynomialCalibration struct {
+	coefficients []float64
+}
+
+// Point is one point of lookup table
+type Point struct {
+	Voltage float64
+	Value   float64
+}
+
+// TableCalibration interpolate linearly between points of lookup table
+type TableCalibration struct {
+	points []Point
+}
+
+// NewLinearCalibration return calibration where value = gain * voltage + offset
+func NewLinearCalibration(gain float64, offset float64) Calibration {
+	return &LinearCalibration{
+		gain:   gain,
+		offset: offset,
+	}
+}
+
+// NewPolynomialCalibration return calibration where value = c0 + c1 * voltage + c2 * voltage^2 + ...
+func NewPolynomialCalibration(coefficients ...float64) (Calibration, error) {
+	if len(coefficients) == 0 {
+		return nil, errors.New("Polynomial calibration need at least one coefficient")
+	}
+
+	return &PolynomialCalibration{
+		coefficients: coefficients,
+	}, nil
+}
+
+// NewTableCalibration return calibration that interpolate linearly between points.
+// Voltage outside the table return the value of the nearest point and ErrOutOfRange
+func NewTableCalibration(points []Point) (Calibration, error) {
+	if len(points) < 2 {
+		return nil, errors.New("Table calibration need at least two points")
+	}
+
+	sorted := make([]Point, len(points))
+	copy(sorted, points)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Voltage < sorted[j].Voltage
+	})
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].Voltage == sorted[i-1].Voltage {
+			return nil, errors.Errorf("Table calibration has duplicate voltage %f", sorted[i].Voltage)
+		}
+	}
+
+	return &TableCalibration{
+		points: sorted,
+	}, nil
+}
+
+// Convert return the value in engineering unit from voltage
+func (c *LinearCalibration) Convert(voltage float64) (float64, error) {
+	return c.gain*voltage + c.offset, nil
+}
+
+// Convert return the value in engineering unit from voltage
+func (c *PolynomialCalibration) Convert(voltage float64) (float64, error) {
+	// Horner method
+	value := 0.0
+	for i := len(c.coefficients) - 1; i >= 0; i-- {
+		value = value*voltage + c.coefficients[i]
+	}
+
+	return value, nil
+}
+
+// Convert return the value in engineering unit from voltage
+func (c *TableCalibration) Convert(voltage float64) (float64, error) {
+	first := c.points[0]
+	last := c.points[len(c.points)-1]
+	if voltage < first.Voltage {
+		return first.Value, errors.Wrapf(ErrOutOfRange, "Voltage %f is outside calibration table [%f, %f]", voltage, first.Voltage, last.Voltage)
+	}
+	if voltage > last.Voltage {
+		return last.Value, errors.Wrapf(ErrOutOfRange, "Voltage %f is outside calibration table [%f, %f]", voltage, first.Voltage, last.Voltage)
+	}
+
+	i := sort.Search(len(c.points), func(i int) bool {
+		return c.points[i].Voltage >= voltage
+	})
+	if i == 0 {
+		return first.Value, nil
+	}
+	low := c.points[i-1]
+	high := c.points[i]
+	ratio := (voltage - low.Voltage) / (high.Voltage - low.Voltage)
+
+	return low.Value + ratio*(high.Value-low.Value), nil
+}
@@ -0,0 +1,59 @@
+package analog
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinearCalibration(t *testing.T) {
+	calibration := NewLinearCalibration(10, -5)
+	value, err := calibration.Convert(2)
+	assert.NoError(t, err)
+	assert.Equal(t, 15.0, value)
+}
+
+func TestPolynomialCalibration(t *testing.T) {
+	_, err := NewPolynomialCalibration()
+	assert.Error(t, err)
+
+	// 1 + 2x + 3x^2
+	calibration, err := NewPolynomialCalibration(1, 2, 3)
+	assert.NoError(t, err)
+	value, err := calibration.Convert(2)
+	assert.NoError(t, err)
+	assert.Equal(t, 17.0, value)
+}
+
+func TestTableCalibration(t *testing.T) {
+	_, err := NewTableCalibration([]Point{{Voltage: 1, Value: 10}})
+	assert.Error(t, err)
+	_, err = NewTableCalibration([]Point{{Voltage: 1, Value: 10}, {Voltage: 1, Value: 20}})
+	assert.Error(t, err)
+
+	// Points are sorted
+	calibration, err := NewTableCalibration([]Point{{Voltage: 3, Value: 0}, {Voltage: 1, Value: 10}, {Voltage: 2, Value: 20}})
+	assert.NoError(t, err)
+
+	value, err := calibration.Convert(1)
+	assert.NoError(t, err)
+	assert.Equal(t, 10.0, value)
+	value, err = calibration.Convert(1.5)
+	assert.NoError(t, err)
+	assert.Equal(t, 15.0, value)
+	value, err = calibration.Convert(2.25)
+	assert.NoError(t, err)
+	assert.Equal(t, 15.0, value)
+	value, err = calibration.Convert(3)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, value)
+
+	// Outside table
+	value, err = calibration.Convert(0.5)
+	assert.Equal(t, ErrOutOfRange, errors.Cause(err))
+	assert.Equal(t, 10.0, value)
+	value, err = calibration.Convert(3.5)
+	assert.Equal(t, ErrOutOfRange, errors.Cause(err))
+	assert.Equal(t, 0.0, value)
+}
@@ -0,0 +1,88 @@
+package analog
+
+// Filter smooth the successive values
+// It's not safe for concurrent use, the device must protect it
+type Filter interface {
+
+	// Update add value and return the smoothed value
+	Update(value float64) (smoothed float64)
+
+	// Reset forget the previous values
+	Reset()
+}
+
+// MovingAverageFilter return the average of the last N values
+type MovingAverageFilter struct {
+	values []float64
+	next   int
+	count  int
+	sum    float64
+}
+
+// ExponentialFilter return smoothed = alpha * value + (1 - alpha) * previous smoothed
+type ExponentialFilter struct {
+	alpha    float64
+	smoothed float64
+	started  bool
+}
+
+// NewMovingAverageFilter return filter that average the last N values
+func NewMovingAverageFilter(size int) Filter {
+	if size < 1 {
+		size = 1
+	}
+
+	return &MovingAverageFilter{
+		values: make([]float64, size),
+	}
+}
+
+// NewExponentialFilter return exponential smoothing filter.
+// Alpha is between 0 and 1, the higher alpha the less smoothing
+func NewExponentialFilter(alpha float64) Filter {
+	if alpha <= 0 || alpha > 1 {
+		alpha = 1
+	}
+
+	return &ExponentialFilter{
+		alpha: alpha,
+	}
+}
+
+// Update add value and return the average of the last N values
+func (f *MovingAverageFilter) Update(value float64) float64 {
+	if f.count == len(f.values) {
+		f.sum -= f.values[f.next]
+	} else {
+		f.count++
+	}
+	f.values[f.next] = value
+	f.sum += value
+	f.next = (f.next + 1) % len(f.values)
+
+	return f.sum / float64(f.count)
+}
+
+// Reset forget the previous values
+func (f *MovingAverageFilter) Reset() {
+	f.next = 0
+	f.count = 0
+	f.sum = 0
+}
+
+// Update add value and return the smoothed value
+func (f *ExponentialFilter) Update(value float64) float64 {
+	if !f.started {
+		f.smoothed = value
+		f.started = true
+	} else {
+		f.smoothed = f.alpha*value + (1-f.alpha)*f.smoothed
+	}
+
+	return f.smoothed
+}
+
+// Reset forget the previous values
+func (f *ExponentialFilter) Reset() {
+	f.started = false
+}
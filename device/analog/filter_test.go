@@ -0,0 +1,41 @@
+package analog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMovingAverageFilter(t *testing.T) {
+	filter := NewMovingAverageFilter(3)
+
+	// Window not full
+	assert.Equal(t, 3.0, filter.Update(3))
+	assert.Equal(t, 4.5, filter.Update(6))
+	assert.Equal(t, 6.0, filter.Update(9))
+
+	// Oldest value is removed
+	assert.Equal(t, 9.0, filter.Update(12))
+
+	// Reset
+	filter.Reset()
+	assert.Equal(t, 1.0, filter.Update(1))
+}
+
+func TestExponentialFilter(t *testing.T) {
+	filter := NewExponentialFilter(0.5)
+
+	// First value is not smoothed
+	assert.Equal(t, 10.0, filter.Update(10))
+	assert.Equal(t, 15.0, filter.Update(20))
+	assert.Equal(t, 17.5, filter.Update(20))
+
+	// Reset
+	filter.Reset()
+	assert.Equal(t, 0.0, filter.Update(0))
+
+	// Bad alpha disable smoothing
+	filter = NewExponentialFilter(0)
+	filter.Update(10)
+	assert.Equal(t, 20.0, filter.Update(20))
+}
@@ -0,0 +1,68 @@
+package thermistor
+
+import (
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+// absoluteZero is 0°C in kelvin
+const absoluteZero = 273.15
+
+// Model convert the thermistor resistance to temperature
+type Model interface {
+
+	// Kelvin return the temperature in kelvin from resistance in ohm
+	Kelvin(resistance float64) (kelvin float64)
+}
+
+// BetaModel is 1/T = 1/T0 + ln(R/R0)/B
+type BetaModel struct {
+	beta        float64
+	resistance0 float64
+	kelvin0     float64
+}
+
+// SteinhartHartModel is 1/T = A + B*ln(R) + C*ln(R)^3
+type SteinhartHartModel struct {
+	a float64
+	b float64
+	c float64
+}
+
+// NewBetaModel return Beta equation model, from the beta coefficient
+// and the resistance at nominal temperature (usually 10k at 25°C)
+func NewBetaModel(beta float64, nominalResistance float64, nominalCelsius float64) (Model, error) {
+	if beta <= 0 {
+		return nil, errors.Errorf("Beta must be positive: %f", beta)
+	}
+	if nominalResistance <= 0 {
+		return nil, errors.Errorf("Nominal resistance must be positive: %f", nominalResistance)
+	}
+
+	return &BetaModel{
+		beta:        beta,
+		resistance0: nominalResistance,
+		kelvin0:     nominalCelsius + absoluteZero,
+	}, nil
+}
+
+// NewSteinhartHartModel return Steinhart-Hart equation model from the A, B and C coefficients
+func NewSteinhartHartModel(a float64, b float64, c float64) Model {
+	return &SteinhartHartModel{
+		a: a,
+		b: b,
+		c: c,
+	}
+}
+
+// Kelvin return the temperature in kelvin from resistance in ohm
+func (m *BetaModel) Kelvin(resistance float64) float64 {
+	return 1 / (1/m.kelvin0 + math.Log(resistance/m.resistance0)/m.beta)
+}
+
+// Kelvin return the temperature in kelvin from resistance in ohm
+func (m *SteinhartHartModel) Kelvin(resistance float64) float64 {
+	lnR := math.Log(resistance)
+	return 1 / (m.a + m.b*lnR + m.c*lnR*lnR*lnR)
+}
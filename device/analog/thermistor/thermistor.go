@@ -0,0 +1,168 @@
+package thermistor
+
+import (
+	"sync"
+
+	"github.com/disaster37/go-arest"
+	"github.com/disaster37/go-arest/device/analog"
+	"github.com/pkg/errors"
+)
+
+// Divider orientations
+const (
+	// ThermistorToGround is when thermistor is between analog pin and ground, and series resistor between Vcc and analog pin
+	ThermistorToGround string = "ground"
+
+	// ThermistorToVcc is when thermistor is between Vcc and analog pin, and series resistor between analog pin and ground
+	ThermistorToVcc string = "vcc"
+)
+
+// faultMargin is the ratio of the ADC range, near 0 or max, where the circuit is considered open or short
+const faultMargin = 0.01
+
+var (
+	// ErrOpenCircuit is returned when thermistor is disconnected
+	ErrOpenCircuit = errors.New("Thermistor open circuit")
+
+	// ErrShortCircuit is returned when thermistor is shorted
+	ErrShortCircuit = errors.New("Thermistor short circuit")
+)
+
+// Sensor is the thermistor temperature sensor interface
+type Sensor interface {
+
+	// Read read the pin and return the temperature in °C
+	// It return ErrOpenCircuit or ErrShortCircuit on wiring fault
+	Read() (celsius float64, err error)
+
+	// Celsius return the last temperature in °C
+	Celsius() (celsius float64)
+
+	// Fahrenheit return the last temperature in °F
+	Fahrenheit() (fahrenheit float64)
+
+	// Resistance return the last thermistor resistance in ohm
+	Resistance() (resistance float64)
+}
+
+// SensorImp is the default Sensor implementation
+// It's safe for concurrent use
+type SensorImp struct {
+	pin            int
+	client         arest.Arest
+	maxCount       int
+	seriesResistor float64
+	orientation    string
+	model          Model
+	filter         analog.Filter
+	resistance     float64
+	celsius        float64
+	mutex          sync.Mutex
+}
+
+// NewSensor return new thermistor sensor on voltage divider.
+// Resolution is the board ADC resolution in bits. The divider must be supplied by the ADC reference voltage
+func NewSensor(client arest.Arest, pin int, resolution int, seriesResistor float64, orientation string, model Model) (Sensor, error) {
+	if resolution < 1 || resolution > 24 {
+		return nil, errors.Errorf("ADC resolution must be between 1 and 24 bits: %d", resolution)
+	}
+	if seriesResistor <= 0 {
+		return nil, errors.Errorf("Series resistor must be positive: %f", seriesResistor)
+	}
+	if orientation != ThermistorToGround && orientation != ThermistorToVcc {
+		return nil, errors.Errorf("Divider orientation %s not supported", orientation)
+	}
+	if model == nil {
+		return nil, errors.New("Thermistor model can't be nil")
+	}
+
+	return &SensorImp{
+		pin:            pin,
+		client:         client,
+		maxCount:       1<<uint(resolution) - 1,
+		seriesResistor: seriesResistor,
+		orientation:    orientation,
+		model:          model,
+	}, nil
+}
+
+// SetFilter permit to smooth the temperatures
+func (h *SensorImp) SetFilter(filter analog.Filter) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.filter = filter
+}
+
+// Read read the pin and return the temperature in °C
+// It return ErrOpenCircuit or ErrShortCircuit on wiring fault
+func (h *SensorImp) Read() (celsius float64, err error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	raw, err := h.client.AnalogRead(h.pin)
+	if err != nil {
+		return 0, err
+	}
+	ratio := float64(raw) / float64(h.maxCount)
+
+	// Near the ADC limits, the thermistor is open or shorted
+	low := ratio <= faultMargin
+	high := ratio >= 1-faultMargin
+	if low || high {
+		isOpen := high
+		if h.orientation == ThermistorToVcc {
+			isOpen = low
+		}
+		if isOpen {
+			return 0, errors.Wrapf(ErrOpenCircuit, "ADC read %d on pin %d", raw, h.pin)
+		}
+		return 0, errors.Wrapf(ErrShortCircuit, "ADC read %d on pin %d", raw, h.pin)
+	}
+
+	var resistance float64
+	if h.orientation == ThermistorToGround {
+		resistance = h.seriesResistor * ratio / (1 - ratio)
+	} else {
+		resistance = h.seriesResistor * (1 - ratio) / ratio
+	}
+
+	celsius = h.model.Kelvin(resistance) - absoluteZero
+	if h.filter != nil {
+		celsius = h.filter.Update(celsius)
+	}
+
+	h.resistance = resistance
+	h.celsius = celsius
+
+	return celsius, nil
+}
+
+// Celsius return the last temperature in °C
+func (h *SensorImp) Celsius() float64 {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return h.celsius
+}
+
+// Fahrenheit return the last temperature in °F
+func (h *SensorImp) Fahrenheit() float64 {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return CelsiusToFahrenheit(h.celsius)
+}
+
+// Resistance return the last thermistor resistance in ohm
+func (h *SensorImp) Resistance() float64 {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return h.resistance
+}
+
+// CelsiusToFahrenheit convert temperature from °C to °F
+func CelsiusToFahrenheit(celsius float64) float64 {
+	return celsius*9/5 + 32
+}
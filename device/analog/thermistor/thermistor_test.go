@@ -0,0 +1,110 @@
+package thermistor
+
+import (
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/disaster37/go-arest/device/analog"
+	"github.com/disaster37/go-arest/rest"
+	"github.com/jarcoal/httpmock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModel(t *testing.T) {
+	_, err := NewBetaModel(0, 10000, 25)
+	assert.Error(t, err)
+	_, err = NewBetaModel(3950, 0, 25)
+	assert.Error(t, err)
+
+	// Nominal resistance give nominal temperature
+	model, err := NewBetaModel(3950, 10000, 25)
+	assert.NoError(t, err)
+	assert.InDelta(t, 298.15, model.Kelvin(10000), 0.001)
+	assert.InDelta(t, 273.15, model.Kelvin(33620), 1)
+
+	model = NewSteinhartHartModel(1.009249522e-03, 2.378405444e-04, 2.019202697e-07)
+	assert.InDelta(t, 24.68+273.15, model.Kelvin(10000), 0.01)
+}
+
+func TestSensor(t *testing.T) {
+	client := rest.MockRestClient()
+	var rawValue int32
+	httpmock.RegisterResponder("GET", "http://localhost/analog/2", func(req *http.Request) (*http.Response, error) {
+		return httpmock.NewJsonResponse(200, map[string]interface{}{
+			"return_value": atomic.LoadInt32(&rawValue),
+		})
+	})
+	model, err := NewBetaModel(3950, 10000, 25)
+	assert.NoError(t, err)
+
+	// Bad settings
+	_, err = NewSensor(client, 2, 0, 10000, ThermistorToGround, model)
+	assert.Error(t, err)
+	_, err = NewSensor(client, 2, 10, 0, ThermistorToGround, model)
+	assert.Error(t, err)
+	_, err = NewSensor(client, 2, 10, 10000, "bad", model)
+	assert.Error(t, err)
+	_, err = NewSensor(client, 2, 10, 10000, ThermistorToGround, nil)
+	assert.Error(t, err)
+
+	// Thermistor to ground
+	sensor, err := NewSensor(client, 2, 10, 10000, ThermistorToGround, model)
+	assert.NoError(t, err)
+	atomic.StoreInt32(&rawValue, 511)
+	celsius, err := sensor.Read()
+	assert.NoError(t, err)
+	assert.InDelta(t, 25, celsius, 0.1)
+	assert.InDelta(t, 25, sensor.Celsius(), 0.1)
+	assert.InDelta(t, 77, sensor.Fahrenheit(), 0.2)
+	assert.InDelta(t, 9980, sensor.Resistance(), 1)
+
+	// Lower resistance is hotter
+	atomic.StoreInt32(&rawValue, 300)
+	celsius, err = sensor.Read()
+	assert.NoError(t, err)
+	assert.True(t, celsius > 25)
+
+	// Open and short circuit
+	atomic.StoreInt32(&rawValue, 1023)
+	_, err = sensor.Read()
+	assert.Equal(t, ErrOpenCircuit, errors.Cause(err))
+	atomic.StoreInt32(&rawValue, 0)
+	_, err = sensor.Read()
+	assert.Equal(t, ErrShortCircuit, errors.Cause(err))
+
+	// Thermistor to Vcc
+	sensor, err = NewSensor(client, 2, 10, 10000, ThermistorToVcc, model)
+	assert.NoError(t, err)
+	atomic.StoreInt32(&rawValue, 511)
+	_, err = sensor.Read()
+	assert.NoError(t, err)
+	assert.InDelta(t, 10020, sensor.Resistance(), 1)
+	atomic.StoreInt32(&rawValue, 700)
+	celsius, err = sensor.Read()
+	assert.NoError(t, err)
+	assert.True(t, celsius > 25)
+	atomic.StoreInt32(&rawValue, 0)
+	_, err = sensor.Read()
+	assert.Equal(t, ErrOpenCircuit, errors.Cause(err))
+	atomic.StoreInt32(&rawValue, 1023)
+	_, err = sensor.Read()
+	assert.Equal(t, ErrShortCircuit, errors.Cause(err))
+
+	// Smoothing
+	sensor.(*SensorImp).SetFilter(analog.NewMovingAverageFilter(2))
+	atomic.StoreInt32(&rawValue, 511)
+	first, err := sensor.Read()
+	assert.NoError(t, err)
+	atomic.StoreInt32(&rawValue, 700)
+	smoothed, err := sensor.Read()
+	assert.NoError(t, err)
+	assert.True(t, smoothed > first)
+	assert.True(t, smoothed < celsius)
+}
+
+func TestCelsiusToFahrenheit(t *testing.T) {
+	assert.Equal(t, 32.0, CelsiusToFahrenheit(0))
+	assert.Equal(t, 212.0, CelsiusToFahrenheit(100))
+}
@@ -0,0 +1,65 @@
+package device
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock return the current time
+// It permit to control the time in tests
+type Clock interface {
+
+	// Now return the current time
+	Now() time.Time
+}
+
+// ClockImp is the Clock implementation based on system time
+type ClockImp struct{}
+
+// NewClock return the system clock
+func NewClock() Clock {
+	return &ClockImp{}
+}
+
+// Now return the current time
+func (c *ClockImp) Now() time.Time {
+	return time.Now()
+}
+
+// FakeClock is the Clock implementation where time only move when asked
+// It's safe for concurrent use
+type FakeClock struct {
+	now   time.Time
+	mutex sync.Mutex
+}
+
+// NewFakeClock return clock set to now
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{
+		now: now,
+	}
+}
+
+// Now return the current time
+func (c *FakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.now
+}
+
+// Set move the clock to now
+func (c *FakeClock) Set(now time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.now = now
+}
+
+// Add move the clock forward by duration
+func (c *FakeClock) Add(duration time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.now = c.now.Add(duration)
+}
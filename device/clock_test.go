@@ -0,0 +1,20 @@
+package device
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeClock(t *testing.T) {
+	now := time.Now()
+	clock := NewFakeClock(now)
+	assert.Equal(t, now, clock.Now())
+
+	clock.Add(1 * time.Minute)
+	assert.Equal(t, now.Add(1*time.Minute), clock.Now())
+
+	clock.Set(now)
+	assert.Equal(t, now, clock.Now())
+}
@@ -0,0 +1,159 @@
+package device
+
+import (
+	"time"
+)
+
+// Debouncer filter raw digital samples to remove contact bounce
+// It's not safe for concurrent use, the device must protect it
+type Debouncer interface {
+
+	// Update add raw sample read at time now and return the debounced state
+	Update(sample bool, now time.Time) (state bool)
+
+	// State return the debounced state
+	State() (state bool)
+
+	// Reset set the debounced state and forget the pending samples
+	Reset(state bool)
+}
+
+// StableTimeDebouncer change state when samples stay the same during window
+type StableTimeDebouncer struct {
+	window    time.Duration
+	state     bool
+	candidate bool
+	since     time.Time
+}
+
+// SampleDebouncer change state after N consecutive matching samples
+type SampleDebouncer struct {
+	samples int
+	state   bool
+	count   int
+}
+
+// IntegratorDebouncer count up on true sample and down on false sample.
+// State change to true when counter reach max, and to false when it reach 0
+type IntegratorDebouncer struct {
+	max     int
+	state   bool
+	counter int
+}
+
+// NewStableTimeDebouncer return debouncer that change state when samples stay the same during window
+func NewStableTimeDebouncer(window time.Duration) Debouncer {
+	return &StableTimeDebouncer{
+		window: window,
+	}
+}
+
+// NewSampleDebouncer return debouncer that change state after N consecutive matching samples
+func NewSampleDebouncer(samples int) Debouncer {
+	if samples < 1 {
+		samples = 1
+	}
+
+	return &SampleDebouncer{
+		samples: samples,
+	}
+}
+
+// NewIntegratorDebouncer return debouncer that integrate samples between 0 and max
+func NewIntegratorDebouncer(max int) Debouncer {
+	if max < 1 {
+		max = 1
+	}
+
+	return &IntegratorDebouncer{
+		max: max,
+	}
+}
+
+// Update add raw sample read at time now and return the debounced state
+func (d *StableTimeDebouncer) Update(sample bool, now time.Time) bool {
+	if sample == d.state {
+		d.candidate = d.state
+		return d.state
+	}
+
+	if sample != d.candidate {
+		d.candidate = sample
+		d.since = now
+	}
+	if now.Sub(d.since) >= d.window {
+		d.state = sample
+	}
+
+	return d.state
+}
+
+// State return the debounced state
+func (d *StableTimeDebouncer) State() bool {
+	return d.state
+}
+
+// Reset set the debounced state and forget the pending samples
+func (d *StableTimeDebouncer) Reset(state bool) {
+	d.state = state
+	d.candidate = state
+}
+
+// Update add raw sample and return the debounced state
+func (d *SampleDebouncer) Update(sample bool, now time.Time) bool {
+	if sample == d.state {
+		d.count = 0
+		return d.state
+	}
+
+	d.count++
+	if d.count >= d.samples {
+		d.state = sample
+		d.count = 0
+	}
+
+	return d.state
+}
+
+// State return the debounced state
+func (d *SampleDebouncer) State() bool {
+	return d.state
+}
+
+// Reset set the debounced state and forget the pending samples
+func (d *SampleDebouncer) Reset(state bool) {
+	d.state = state
+	d.count = 0
+}
+
+// Update add raw sample and return the debounced state
+func (d *IntegratorDebouncer) Update(sample bool, now time.Time) bool {
+	if sample && d.counter < d.max {
+		d.counter++
+	} else if !sample && d.counter > 0 {
+		d.counter--
+	}
+
+	if d.counter == d.max {
+		d.state = true
+	} else if d.counter == 0 {
+		d.state = false
+	}
+
+	return d.state
+}
+
+// State return the debounced state
+func (d *IntegratorDebouncer) State() bool {
+	return d.state
+}
+
+// Reset set the debounced state and forget the pending samples
+func (d *IntegratorDebouncer) Reset(state bool) {
+	d.state = state
+	if state {
+		d.counter = d.max
+	} else {
+		d.counter = 0
+	}
+}
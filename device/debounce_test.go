@@ -0,0 +1,72 @@
+package device
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStableTimeDebouncer(t *testing.T) {
+	debouncer := NewStableTimeDebouncer(50 * time.Millisecond)
+	now := time.Now()
+
+	// Bounce is ignored
+	assert.Equal(t, false, debouncer.Update(true, now))
+	assert.Equal(t, false, debouncer.Update(false, now.Add(10*time.Millisecond)))
+	assert.Equal(t, false, debouncer.Update(true, now.Add(20*time.Millisecond)))
+	assert.Equal(t, false, debouncer.Update(true, now.Add(60*time.Millisecond)))
+
+	// Stable during window
+	assert.Equal(t, true, debouncer.Update(true, now.Add(70*time.Millisecond)))
+	assert.Equal(t, true, debouncer.State())
+
+	// Reset
+	debouncer.Reset(false)
+	assert.Equal(t, false, debouncer.State())
+	assert.Equal(t, false, debouncer.Update(true, now.Add(100*time.Millisecond)))
+}
+
+func TestSampleDebouncer(t *testing.T) {
+	debouncer := NewSampleDebouncer(3)
+	now := time.Now()
+
+	// Bounce is ignored
+	assert.Equal(t, false, debouncer.Update(true, now))
+	assert.Equal(t, false, debouncer.Update(true, now))
+	assert.Equal(t, false, debouncer.Update(false, now))
+	assert.Equal(t, false, debouncer.Update(true, now))
+	assert.Equal(t, false, debouncer.Update(true, now))
+
+	// N consecutive samples
+	assert.Equal(t, true, debouncer.Update(true, now))
+	assert.Equal(t, true, debouncer.State())
+	assert.Equal(t, true, debouncer.Update(false, now))
+
+	// Reset
+	debouncer.Reset(false)
+	assert.Equal(t, false, debouncer.Update(true, now))
+}
+
+func TestIntegratorDebouncer(t *testing.T) {
+	debouncer := NewIntegratorDebouncer(3)
+	now := time.Now()
+
+	// Integrate until max
+	assert.Equal(t, false, debouncer.Update(true, now))
+	assert.Equal(t, false, debouncer.Update(true, now))
+	assert.Equal(t, false, debouncer.Update(false, now))
+	assert.Equal(t, false, debouncer.Update(true, now))
+	assert.Equal(t, true, debouncer.Update(true, now))
+	assert.Equal(t, true, debouncer.Update(true, now))
+
+	// Integrate until 0
+	assert.Equal(t, true, debouncer.Update(false, now))
+	assert.Equal(t, true, debouncer.Update(false, now))
+	assert.Equal(t, false, debouncer.Update(false, now))
+	assert.Equal(t, false, debouncer.State())
+
+	// Reset
+	debouncer.Reset(true)
+	assert.Equal(t, true, debouncer.Update(false, now))
+}
@@ -0,0 +1,89 @@
+package device
+
+import (
+	"context"
+	"sync"
+)
+
+// Emitter call handlers and send events on channel.
+// It's shared by devices that emit events, so they only have to compute them
+// It's safe for concurrent use
+type Emitter[T any] struct {
+	handlers []func(event T)
+	events   chan T
+	isDone   bool
+	mutex    sync.Mutex
+}
+
+// NewEmitter return new emitter without handler
+func NewEmitter[T any]() *Emitter[T] {
+	return &Emitter[T]{
+		handlers: make([]func(event T), 0),
+	}
+}
+
+// OnEvent add handler called on each event
+func (e *Emitter[T]) OnEvent(handler func(event T)) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.handlers = append(e.handlers, handler)
+}
+
+// Events return the channel where events are sent
+// The channel is created on the first call, so events are not buffered when nobody listen.
+// It's closed by Close
+func (e *Emitter[T]) Events() <-chan T {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if e.events == nil {
+		e.events = make(chan T, 100)
+
+		// Nothing will be sent anymore
+		if e.isDone {
+			close(e.events)
+		}
+	}
+
+	return e.events
+}
+
+// Emit call handlers and send event on channel, until context is done when channel is full
+// Handlers are called without lock, so they can use the emitter
+func (e *Emitter[T]) Emit(ctx context.Context, event T) {
+	e.mutex.Lock()
+	if e.isDone {
+		e.mutex.Unlock()
+		return
+	}
+	handlers := e.handlers
+	events := e.events
+	e.mutex.Unlock()
+
+	for _, handler := range handlers {
+		handler(event)
+	}
+
+	if events != nil {
+		select {
+		case events <- event:
+		case <-ctx.Done():
+		}
+	}
+}
+
+// Close close the events channel. Event emitted after are dropped
+// It must not be called while Emit is running
+func (e *Emitter[T]) Close() {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if e.isDone {
+		return
+	}
+	e.isDone = true
+	if e.events != nil {
+		close(e.events)
+	}
+}
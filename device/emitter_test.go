@@ -0,0 +1,50 @@
+package device
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmitter(t *testing.T) {
+	emitter := NewEmitter[int]()
+	received := make([]int, 0)
+	emitter.OnEvent(func(event int) {
+		received = append(received, event)
+		// Handler can use the emitter
+		emitter.Events()
+	})
+
+	// Handlers are called, event is sent on channel
+	events := emitter.Events()
+	emitter.Emit(context.Background(), 1)
+	assert.Equal(t, []int{1}, received)
+	assert.Equal(t, 1, <-events)
+
+	// Channel full, context done
+	ctx, cancel := context.WithCancel(context.Background())
+	for i := 0; i < 100; i++ {
+		emitter.Emit(ctx, i)
+	}
+	cancel()
+	emitter.Emit(ctx, 100)
+	assert.Equal(t, 102, len(received))
+
+	// Closed
+	emitter.Close()
+	emitter.Close()
+	emitter.Emit(context.Background(), 101)
+	assert.Equal(t, 102, len(received))
+	nbEvent := 0
+	for range events {
+		nbEvent++
+	}
+	assert.Equal(t, 100, nbEvent)
+
+	// Events after close return closed channel
+	emitter = NewEmitter[int]()
+	emitter.Close()
+	_, ok := <-emitter.Events()
+	assert.False(t, ok)
+}
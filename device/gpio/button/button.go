@@ -1,6 +1,11 @@
 package button
 
-import "github.com/disaster37/go-arest"
+import (
+	"sync"
+
+	"github.com/disaster37/go-arest"
+	"github.com/disaster37/go-arest/device"
+)
 
 // Button is the button interface
 type Button interface {
@@ -12,6 +17,7 @@ type Button interface {
 }
 
 // ButtonImp is the default Button implementation
+// It's safe for concurrent use
 type ButtonImp struct {
 	pin         int
 	client      arest.Arest
@@ -20,6 +26,9 @@ type ButtonImp struct {
 	isPushed    bool
 	isReleazed  bool
 	state       bool
+	debouncer   device.Debouncer
+	clock       device.Clock
+	mutex       sync.Mutex
 }
 
 func NewButton(client arest.Arest, pin int, signal arest.Level, inputPullup bool) (Button, error) {
@@ -43,30 +52,65 @@ func NewButton(client arest.Arest, pin int, signal arest.Level, inputPullup bool
 		isPushed:    false,
 		isReleazed:  false,
 		state:       false,
+		clock:       device.NewClock(),
 	}, nil
 }
 
+// SetDebouncer permit to filter contact bounce between reads
+// The debouncer is applied after signal and pullup logic
+func (h *ButtonImp) SetDebouncer(debouncer device.Debouncer) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	debouncer.Reset(h.state)
+	h.debouncer = debouncer
+}
+
+// SetClock permit to use custom clock for the debouncer
+func (h *ButtonImp) SetClock(clock device.Clock) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.clock = clock
+}
+
 // IsPushed return true if button just to be pushed
 func (h *ButtonImp) IsPushed() (state bool) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
 	return h.isPushed
 }
 
 // IsReleazed return true if button jus to be releazed
 func (h *ButtonImp) IsReleazed() (state bool) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
 	return h.isReleazed
 }
 
 // IsUp return true if button  is UP
 func (h *ButtonImp) IsUp() (state bool) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
 	return !h.state
 }
 
 // IsDown return true if button is down
 func (h *ButtonImp) IsDown() (state bool) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
 	return h.state
 }
 
+// Read read the button level and compute its state
 func (h *ButtonImp) Read() error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
 	level, err := h.client.DigitalRead(h.pin)
 	if err != nil {
 		return err
@@ -81,6 +125,9 @@ func (h *ButtonImp) Read() error {
 	if h.inputPullup {
 		computedLevel = !computedLevel
 	}
+	if h.debouncer != nil {
+		computedLevel = h.debouncer.Update(computedLevel, h.clock.Now())
+	}
 
 	if computedLevel && !h.state {
 		// Just push button
@@ -1,9 +1,13 @@
 package button
 
 import (
+	"net/http"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/disaster37/go-arest"
+	"github.com/disaster37/go-arest/device"
 	"github.com/disaster37/go-arest/rest"
 	"github.com/jarcoal/httpmock"
 	"github.com/sirupsen/logrus"
@@ -332,3 +336,104 @@ func TestInputPullupButtonLow(t *testing.T) {
 	assert.Equal(t, false, button.IsPushed())
 	assert.Equal(t, false, button.IsReleazed())
 }
+
+func TestButtonConcurrent(t *testing.T) {
+	client := rest.MockRestClient()
+	signal := arest.NewLevel()
+	signal.SetLevelHigh()
+	httpmock.RegisterResponder("POST", "http://localhost/mode/1/i", httpmock.NewStringResponder(200, `{}`))
+	httpmock.RegisterResponder("GET", "http://localhost/digital/1", httpmock.NewJsonResponderOrPanic(200, map[string]interface{}{
+		"return_value": 1,
+	}))
+
+	button, err := NewButton(client, 1, signal, false)
+	assert.NoError(t, err)
+
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				assert.NoError(t, button.Read())
+				button.IsPushed()
+				button.IsReleazed()
+				button.IsUp()
+				button.IsDown()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, true, button.IsDown())
+}
+
+func TestButtonDebounce(t *testing.T) {
+	client := rest.MockRestClient()
+	signal := arest.NewLevel()
+	signal.SetLevelLow()
+	rawLevel := 0
+	httpmock.RegisterResponder("POST", "http://localhost/mode/2/I", httpmock.NewStringResponder(200, `{}`))
+	httpmock.RegisterResponder("GET", "http://localhost/digital/2", func(req *http.Request) (*http.Response, error) {
+		return httpmock.NewJsonResponse(200, map[string]interface{}{
+			"return_value": rawLevel,
+		})
+	})
+
+	// Pullup and low signal, so button is down when pin is high
+	button, err := NewButton(client, 2, signal, true)
+	assert.NoError(t, err)
+	button.(*ButtonImp).SetDebouncer(device.NewSampleDebouncer(2))
+
+	// Bounce is ignored
+	for _, level := range []int{1, 0, 1, 0} {
+		rawLevel = level
+		assert.NoError(t, button.Read())
+		assert.Equal(t, false, button.IsPushed())
+		assert.Equal(t, true, button.IsUp())
+	}
+
+	// Stable level push the button
+	rawLevel = 1
+	assert.NoError(t, button.Read())
+	assert.Equal(t, false, button.IsPushed())
+	assert.NoError(t, button.Read())
+	assert.Equal(t, true, button.IsPushed())
+	assert.Equal(t, true, button.IsDown())
+
+	// Releaze need consecutive samples too
+	rawLevel = 0
+	assert.NoError(t, button.Read())
+	assert.Equal(t, false, button.IsReleazed())
+	assert.Equal(t, true, button.IsDown())
+	assert.NoError(t, button.Read())
+	assert.Equal(t, true, button.IsReleazed())
+	assert.Equal(t, true, button.IsUp())
+}
+
+func TestButtonDebounceClock(t *testing.T) {
+	client := rest.MockRestClient()
+	signal := arest.NewLevel()
+	signal.SetLevelHigh()
+	rawLevel := 0
+	httpmock.RegisterResponder("POST", "http://localhost/mode/3/i", httpmock.NewStringResponder(200, `{}`))
+	httpmock.RegisterResponder("GET", "http://localhost/digital/3", func(req *http.Request) (*http.Response, error) {
+		return httpmock.NewJsonResponse(200, map[string]interface{}{
+			"return_value": rawLevel,
+		})
+	})
+
+	button, err := NewButton(client, 3, signal, false)
+	assert.NoError(t, err)
+	clock := device.NewFakeClock(time.Now())
+	button.(*ButtonImp).SetClock(clock)
+	button.(*ButtonImp).SetDebouncer(device.NewStableTimeDebouncer(50 * time.Millisecond))
+
+	// Level must be stable during the window on the button clock
+	rawLevel = 1
+	assert.NoError(t, button.Read())
+	assert.Equal(t, true, button.IsUp())
+	clock.Add(60 * time.Millisecond)
+	assert.NoError(t, button.Read())
+	assert.Equal(t, true, button.IsPushed())
+}
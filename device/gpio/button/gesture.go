@@ -0,0 +1,130 @@
+package button
+
+import (
+	"time"
+
+	"github.com/disaster37/go-arest/device"
+)
+
+// Gesture types
+const (
+	GestureClick       string = "click"
+	GestureDoubleClick string = "double_click"
+	GestureMultiClick  string = "multi_click"
+	GestureLongPress   string = "long_press"
+	GestureHoldRepeat  string = "hold_repeat"
+)
+
+// Gesture is the gesture detected on button
+type Gesture struct {
+	// Type is the gesture type
+	Type string
+
+	// Clicks is the number of clicks for click gestures
+	Clicks int
+
+	// Repeat is the number of repeat since long press for hold repeat gesture
+	Repeat int
+
+	// Time is when gesture is detected
+	Time time.Time
+}
+
+// GestureDetector read button and detect gestures
+type GestureDetector interface {
+
+	// Read read the button and return the detected gestures
+	// It must be called periodically, faster than the gesture timeouts
+	Read() (gestures []Gesture, err error)
+}
+
+// GestureDetectorImp implement the gesture detector interface
+type GestureDetectorImp struct {
+	button         Button
+	clock          device.Clock
+	clickTimeout   time.Duration
+	longPress      time.Duration
+	repeatInterval time.Duration
+	clicks         int
+	pushedAt       time.Time
+	releazedAt     time.Time
+	isLongPress    bool
+	repeat         int
+	nextRepeat     time.Time
+}
+
+// NewGestureDetector return new gesture detector.
+// Clicks are grouped while next push come before clickTimeout.
+// Long press is detected when button is down during longPress,
+// then hold repeat is emitted every repeatInterval while button is down. Use 0 to disable hold repeat.
+// When button has time based debouncer, it must use the same clock
+func NewGestureDetector(button Button, clock device.Clock, clickTimeout time.Duration, longPress time.Duration, repeatInterval time.Duration) GestureDetector {
+	return &GestureDetectorImp{
+		button:         button,
+		clock:          clock,
+		clickTimeout:   clickTimeout,
+		longPress:      longPress,
+		repeatInterval: repeatInterval,
+	}
+}
+
+// Read read the button and return the detected gestures
+func (h *GestureDetectorImp) Read() (gestures []Gesture, err error) {
+	err = h.button.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	now := h.clock.Now()
+	gestures = make([]Gesture, 0)
+
+	if h.button.IsPushed() {
+		h.pushedAt = now
+		h.isLongPress = false
+	}
+
+	if h.button.IsDown() {
+		if !h.isLongPress && now.Sub(h.pushedAt) >= h.longPress {
+			// Long press cancel the pending clicks
+			h.isLongPress = true
+			h.clicks = 0
+			h.repeat = 0
+			h.nextRepeat = now.Add(h.repeatInterval)
+			gestures = append(gestures, Gesture{
+				Type: GestureLongPress,
+				Time: now,
+			})
+		} else if h.isLongPress && h.repeatInterval > 0 && !now.Before(h.nextRepeat) {
+			h.repeat++
+			h.nextRepeat = h.nextRepeat.Add(h.repeatInterval)
+			gestures = append(gestures, Gesture{
+				Type:   GestureHoldRepeat,
+				Repeat: h.repeat,
+				Time:   now,
+			})
+		}
+	}
+
+	if h.button.IsReleazed() && !h.isLongPress {
+		h.clicks++
+		h.releazedAt = now
+	}
+
+	if h.button.IsUp() && h.clicks > 0 && now.Sub(h.releazedAt) >= h.clickTimeout {
+		gesture := Gesture{
+			Type:   GestureMultiClick,
+			Clicks: h.clicks,
+			Time:   now,
+		}
+		switch h.clicks {
+		case 1:
+			gesture.Type = GestureClick
+		case 2:
+			gesture.Type = GestureDoubleClick
+		}
+		gestures = append(gestures, gesture)
+		h.clicks = 0
+	}
+
+	return gestures, nil
+}
@@ -0,0 +1,96 @@
+package button
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/disaster37/go-arest"
+	"github.com/disaster37/go-arest/device"
+	"github.com/disaster37/go-arest/rest"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGestureDetector(t *testing.T) {
+	client := rest.MockRestClient()
+	signal := arest.NewLevel()
+	signal.SetLevelHigh()
+	rawLevel := 0
+	httpmock.RegisterResponder("POST", "http://localhost/mode/3/i", httpmock.NewStringResponder(200, `{}`))
+	httpmock.RegisterResponder("GET", "http://localhost/digital/3", func(req *http.Request) (*http.Response, error) {
+		return httpmock.NewJsonResponse(200, map[string]interface{}{
+			"return_value": rawLevel,
+		})
+	})
+
+	button, err := NewButton(client, 3, signal, false)
+	assert.NoError(t, err)
+	clock := device.NewFakeClock(time.Now())
+	detector := NewGestureDetector(button, clock, 300*time.Millisecond, 1*time.Second, 200*time.Millisecond)
+
+	// read the button level after delay
+	read := func(level int, delay time.Duration) []Gesture {
+		rawLevel = level
+		clock.Add(delay)
+		gestures, err := detector.Read()
+		assert.NoError(t, err)
+		return gestures
+	}
+	types := func(gestures []Gesture) []string {
+		result := make([]string, 0, len(gestures))
+		for _, gesture := range gestures {
+			result = append(result, gesture.Type)
+		}
+		return result
+	}
+
+	// Click
+	assert.Empty(t, read(0, 0))
+	assert.Empty(t, read(1, 50*time.Millisecond))
+	assert.Empty(t, read(0, 100*time.Millisecond))
+	assert.Empty(t, read(0, 100*time.Millisecond))
+	gestures := read(0, 200*time.Millisecond)
+	assert.Equal(t, []string{GestureClick}, types(gestures))
+	assert.Equal(t, 1, gestures[0].Clicks)
+	assert.Equal(t, clock.Now(), gestures[0].Time)
+
+	// Double click
+	assert.Empty(t, read(1, 100*time.Millisecond))
+	assert.Empty(t, read(0, 100*time.Millisecond))
+	assert.Empty(t, read(1, 100*time.Millisecond))
+	assert.Empty(t, read(0, 100*time.Millisecond))
+	gestures = read(0, 300*time.Millisecond)
+	assert.Equal(t, []string{GestureDoubleClick}, types(gestures))
+	assert.Equal(t, 2, gestures[0].Clicks)
+
+	// Triple click
+	for i := 0; i < 3; i++ {
+		assert.Empty(t, read(1, 100*time.Millisecond))
+		assert.Empty(t, read(0, 100*time.Millisecond))
+	}
+	gestures = read(0, 300*time.Millisecond)
+	assert.Equal(t, []string{GestureMultiClick}, types(gestures))
+	assert.Equal(t, 3, gestures[0].Clicks)
+
+	// Long press with hold repeat cancel the pending click
+	assert.Empty(t, read(1, 100*time.Millisecond))
+	assert.Empty(t, read(0, 100*time.Millisecond))
+	assert.Empty(t, read(1, 100*time.Millisecond))
+	assert.Empty(t, read(1, 500*time.Millisecond))
+	assert.Equal(t, []string{GestureLongPress}, types(read(1, 500*time.Millisecond)))
+	assert.Empty(t, read(1, 100*time.Millisecond))
+	gestures = read(1, 100*time.Millisecond)
+	assert.Equal(t, []string{GestureHoldRepeat}, types(gestures))
+	assert.Equal(t, 1, gestures[0].Repeat)
+	gestures = read(1, 200*time.Millisecond)
+	assert.Equal(t, []string{GestureHoldRepeat}, types(gestures))
+	assert.Equal(t, 2, gestures[0].Repeat)
+	assert.Empty(t, read(0, 100*time.Millisecond))
+	assert.Empty(t, read(0, 1*time.Second))
+
+	// Read error
+	httpmock.Reset()
+	_, err = detector.Read()
+	assert.Error(t, err)
+}
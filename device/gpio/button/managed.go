@@ -0,0 +1,115 @@
+package button
+
+import (
+	"context"
+	"time"
+
+	"github.com/disaster37/go-arest/device"
+	"github.com/pkg/errors"
+)
+
+// Button event types
+const (
+	EventPushed   string = "pushed"
+	EventReleazed string = "releazed"
+)
+
+// Event is emitted when button is pushed or releazed
+type Event struct {
+	Type string
+	Time time.Time
+}
+
+// ManagedButton poll the button in background and emit events
+type ManagedButton interface {
+
+	// OnPush add handler called when button is pushed
+	OnPush(handler func())
+
+	// OnReleaze add handler called when button is releazed
+	OnReleaze(handler func())
+
+	// Events return the channel where events are sent
+	// It's closed when Run return
+	Events() <-chan Event
+
+	// Run poll the button until context is done
+	// It can be run only once, next calls return device.ErrAlreadyRun
+	Run(ctx context.Context) (err error)
+}
+
+// ManagedButtonImp implement the managed button interface
+// It's safe for concurrent use
+type ManagedButtonImp struct {
+	button   Button
+	interval time.Duration
+	poller   *device.Poller
+	emitter  *device.Emitter[Event]
+}
+
+// NewManagedButton return new managed button that read button at interval.
+// When read failed, it wait twice as long before the next read, up to maxBackoff.
+// MaxBackoff lower than interval is raised to interval
+func NewManagedButton(button Button, interval time.Duration, maxBackoff time.Duration) (ManagedButton, error) {
+	if interval <= 0 {
+		return nil, errors.Errorf("Interval must be positive: %s", interval)
+	}
+
+	poller := device.NewPoller()
+	poller.SetMaxBackoff(maxBackoff)
+
+	return &ManagedButtonImp{
+		button:   button,
+		interval: interval,
+		poller:   poller,
+		emitter:  device.NewEmitter[Event](),
+	}, nil
+}
+
+// OnPush add handler called when button is pushed
+func (h *ManagedButtonImp) OnPush(handler func()) {
+	h.onEvent(EventPushed, handler)
+}
+
+// OnReleaze add handler called when button is releazed
+func (h *ManagedButtonImp) OnReleaze(handler func()) {
+	h.onEvent(EventReleazed, handler)
+}
+
+// Events return the channel where events are sent
+// It's closed when Run return
+func (h *ManagedButtonImp) Events() <-chan Event {
+	return h.emitter.Events()
+}
+
+// Run poll the button until context is done
+// It can be run only once, next calls return device.ErrAlreadyRun
+func (h *ManagedButtonImp) Run(ctx context.Context) (err error) {
+	err = h.poller.Run(ctx, h.interval, func() error {
+		if err := h.button.Read(); err != nil {
+			return errors.Wrap(err, "Error when read button")
+		}
+		if h.button.IsPushed() {
+			h.emitter.Emit(ctx, Event{Type: EventPushed, Time: time.Now()})
+		} else if h.button.IsReleazed() {
+			h.emitter.Emit(ctx, Event{Type: EventReleazed, Time: time.Now()})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	h.emitter.Close()
+
+	return nil
+}
+
+// onEvent add handler called on events of type
+func (h *ManagedButtonImp) onEvent(eventType string, handler func()) {
+	h.emitter.OnEvent(func(event Event) {
+		if event.Type == eventType {
+			handler()
+		}
+	})
+}
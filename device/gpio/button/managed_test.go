@@ -0,0 +1,121 @@
+package button
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/disaster37/go-arest"
+	"github.com/disaster37/go-arest/device"
+	"github.com/disaster37/go-arest/rest"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManagedButton(t *testing.T) {
+	client := rest.MockRestClient()
+	signal := arest.NewLevel()
+	signal.SetLevelHigh()
+	var rawLevel, nbRead, isFailed int32
+	httpmock.RegisterResponder("POST", "http://localhost/mode/4/i", httpmock.NewStringResponder(200, `{}`))
+	httpmock.RegisterResponder("GET", "http://localhost/digital/4", func(req *http.Request) (*http.Response, error) {
+		atomic.AddInt32(&nbRead, 1)
+		if atomic.LoadInt32(&isFailed) == 1 {
+			return httpmock.NewStringResponse(500, `{}`), http.ErrHandlerTimeout
+		}
+		return httpmock.NewJsonResponse(200, map[string]interface{}{
+			"return_value": atomic.LoadInt32(&rawLevel),
+		})
+	})
+
+	button, err := NewButton(client, 4, signal, false)
+	assert.NoError(t, err)
+
+	// Bad interval
+	_, err = NewManagedButton(button, 0, 200*time.Millisecond)
+	assert.Error(t, err)
+
+	managed, err := NewManagedButton(button, 10*time.Millisecond, 200*time.Millisecond)
+	assert.NoError(t, err)
+	var nbPush, nbReleaze int32
+	managed.OnPush(func() {
+		atomic.AddInt32(&nbPush, 1)
+	})
+	managed.OnReleaze(func() {
+		atomic.AddInt32(&nbReleaze, 1)
+	})
+	events := managed.Events()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		managed.Run(ctx)
+		close(done)
+	}()
+
+	// Push
+	atomic.StoreInt32(&rawLevel, 1)
+	select {
+	case event := <-events:
+		assert.Equal(t, EventPushed, event.Type)
+		assert.False(t, event.Time.IsZero())
+	case <-time.After(1 * time.Second):
+		assert.Fail(t, "No push event")
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&nbPush))
+
+	// Releaze
+	atomic.StoreInt32(&rawLevel, 0)
+	select {
+	case event := <-events:
+		assert.Equal(t, EventReleazed, event.Type)
+	case <-time.After(1 * time.Second):
+		assert.Fail(t, "No releaze event")
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&nbReleaze))
+
+	// Backoff on read error
+	atomic.StoreInt32(&isFailed, 1)
+	time.Sleep(50 * time.Millisecond)
+	atomic.StoreInt32(&nbRead, 0)
+	time.Sleep(400 * time.Millisecond)
+	assert.True(t, atomic.LoadInt32(&nbRead) <= 4)
+
+	// Stop with context
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(1 * time.Second):
+		assert.Fail(t, "Managed button not stopped")
+	}
+	_, ok := <-events
+	assert.False(t, ok)
+	_, ok = <-managed.Events()
+	assert.False(t, ok)
+	assert.Equal(t, device.ErrAlreadyRun, managed.Run(ctx))
+}
+
+func TestManagedButtonBackoff(t *testing.T) {
+	client := rest.MockRestClient()
+	signal := arest.NewLevel()
+	signal.SetLevelHigh()
+	var nbRead int32
+	httpmock.RegisterResponder("POST", "http://localhost/mode/6/i", httpmock.NewStringResponder(200, `{}`))
+	httpmock.RegisterResponder("GET", "http://localhost/digital/6", func(req *http.Request) (*http.Response, error) {
+		atomic.AddInt32(&nbRead, 1)
+		return nil, http.ErrHandlerTimeout
+	})
+
+	button, err := NewButton(client, 6, signal, false)
+	assert.NoError(t, err)
+
+	// Max backoff lower than interval is raised to interval
+	managed, err := NewManagedButton(button, 20*time.Millisecond, 0)
+	assert.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	assert.NoError(t, managed.Run(ctx))
+	assert.True(t, atomic.LoadInt32(&nbRead) <= 11)
+}
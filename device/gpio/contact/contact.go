@@ -0,0 +1,270 @@
+package contact
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/disaster37/go-arest"
+	"github.com/disaster37/go-arest/device"
+	"github.com/pkg/errors"
+)
+
+// Sensor kinds, to choose the states name
+const (
+	// KindContact is door, window or reed contact, that is closed or open
+	KindContact string = "contact"
+
+	// KindLiquid is float switch or leak sensor, that is wet or dry
+	KindLiquid string = "liquid"
+)
+
+// Sensor states
+const (
+	StateClosed string = "closed"
+	StateOpen   string = "open"
+	StateWet    string = "wet"
+	StateDry    string = "dry"
+)
+
+// Event is emitted when sensor state change
+type Event struct {
+	State  string
+	Active bool
+	Time   time.Time
+
+	// Duration is how long the previous state lasted
+	Duration time.Duration
+}
+
+// Sensor is binary sensor interface
+type Sensor interface {
+
+	// Read read the pin level and compute the sensor state
+	Read() error
+
+	// IsActive return true if contact is closed or wet
+	IsActive() bool
+
+	// State return the state name, or empty string before the first read
+	State() string
+
+	// LastChange return the time of the last state change, or of the first read
+	LastChange() time.Time
+
+	// Since return the time elapsed since the last state change
+	Since() time.Duration
+
+	// OnChange add handler called by Run when state change
+	OnChange(handler func(event Event))
+
+	// Events return the channel where events are sent
+	// It's closed when Run return
+	Events() <-chan Event
+
+	// Run poll the sensor until context is done
+	// It can be run only once, next calls return device.ErrAlreadyRun
+	Run(ctx context.Context, interval time.Duration) (err error)
+}
+
+// SensorImp is the default Sensor implementation
+// It's safe for concurrent use
+type SensorImp struct {
+	pin         int
+	client      arest.Arest
+	kind        string
+	activeLevel arest.Level
+	inputPullup bool
+	debouncer   device.Debouncer
+	clock       device.Clock
+	isRead      bool
+	active      bool
+	lastChange  time.Time
+	poller      *device.Poller
+	emitter     *device.Emitter[Event]
+	mutex       sync.Mutex
+}
+
+// NewSensor return new binary sensor.
+// Active level is the pin level when contact is closed or wet
+func NewSensor(client arest.Arest, pin int, kind string, activeLevel arest.Level, inputPullup bool) (Sensor, error) {
+	if kind != KindContact && kind != KindLiquid {
+		return nil, errors.Errorf("Sensor kind %s not supported", kind)
+	}
+
+	mode := arest.NewMode()
+	if !inputPullup {
+		mode.SetModeInput()
+	} else {
+		mode.SetModeInputPullup()
+	}
+
+	err := client.SetPinMode(pin, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SensorImp{
+		pin:         pin,
+		client:      client,
+		kind:        kind,
+		activeLevel: activeLevel,
+		inputPullup: inputPullup,
+		clock:       device.NewClock(),
+		poller:      device.NewPoller(),
+		emitter:     device.NewEmitter[Event](),
+	}, nil
+}
+
+// SetDebouncer permit to filter contact bounce between reads
+func (h *SensorImp) SetDebouncer(debouncer device.Debouncer) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	debouncer.Reset(h.active)
+	h.debouncer = debouncer
+}
+
+// SetClock permit to use custom clock
+func (h *SensorImp) SetClock(clock device.Clock) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.clock = clock
+}
+
+// Read read the pin level and compute the sensor state
+func (h *SensorImp) Read() error {
+	_, err := h.read()
+	return err
+}
+
+// IsActive return true if contact is closed or wet
+func (h *SensorImp) IsActive() bool {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return h.active
+}
+
+// State return the state name, or empty string before the first read
+func (h *SensorImp) State() string {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if !h.isRead {
+		return ""
+	}
+
+	return h.stateName(h.active)
+}
+
+// LastChange return the time of the last state change, or of the first read
+func (h *SensorImp) LastChange() time.Time {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return h.lastChange
+}
+
+// Since return the time elapsed since the last state change
+func (h *SensorImp) Since() time.Duration {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if !h.isRead {
+		return 0
+	}
+
+	return h.clock.Now().Sub(h.lastChange)
+}
+
+// OnChange add handler called by Run when state change
+func (h *SensorImp) OnChange(handler func(event Event)) {
+	h.emitter.OnEvent(handler)
+}
+
+// Events return the channel where events are sent
+// It's closed when Run return
+func (h *SensorImp) Events() <-chan Event {
+	return h.emitter.Events()
+}
+
+// Run poll the sensor until context is done
+// It can be run only once, next calls return device.ErrAlreadyRun
+func (h *SensorImp) Run(ctx context.Context, interval time.Duration) (err error) {
+	err = h.poller.Run(ctx, interval, func() error {
+		event, err := h.read()
+		if err != nil {
+			return errors.Wrapf(err, "Error when read sensor on pin %d", h.pin)
+		}
+		if event != nil {
+			h.emitter.Emit(ctx, *event)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	h.emitter.Close()
+
+	return nil
+}
+
+// read read the pin level and return event if state change
+func (h *SensorImp) read() (*Event, error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	level, err := h.client.DigitalRead(h.pin)
+	if err != nil {
+		return nil, err
+	}
+
+	now := h.clock.Now()
+	active := level.IsHigh() == h.activeLevel.IsHigh()
+
+	// First read is the initial state, not a change
+	if !h.isRead {
+		h.isRead = true
+		h.active = active
+		h.lastChange = now
+		if h.debouncer != nil {
+			h.debouncer.Reset(active)
+		}
+		return nil, nil
+	}
+
+	if h.debouncer != nil {
+		active = h.debouncer.Update(active, now)
+	}
+	if active == h.active {
+		return nil, nil
+	}
+
+	event := &Event{
+		State:    h.stateName(active),
+		Active:   active,
+		Time:     now,
+		Duration: now.Sub(h.lastChange),
+	}
+	h.active = active
+	h.lastChange = now
+
+	return event, nil
+}
+
+// stateName return the state name from the sensor kind
+func (h *SensorImp) stateName(active bool) string {
+	switch {
+	case h.kind == KindContact && active:
+		return StateClosed
+	case h.kind == KindContact:
+		return StateOpen
+	case active:
+		return StateWet
+	default:
+		return StateDry
+	}
+}
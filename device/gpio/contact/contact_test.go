@@ -0,0 +1,101 @@
+package contact
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/disaster37/go-arest"
+	"github.com/disaster37/go-arest/device"
+	"github.com/disaster37/go-arest/rest"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSensor(t *testing.T) {
+	client := rest.MockRestClient()
+	var rawLevel int32
+	httpmock.RegisterResponder("POST", "http://localhost/mode/2/I", httpmock.NewStringResponder(200, `{}`))
+	httpmock.RegisterResponder("POST", "http://localhost/mode/3/i", httpmock.NewStringResponder(200, `{}`))
+	readLevel := func(req *http.Request) (*http.Response, error) {
+		return httpmock.NewJsonResponse(200, map[string]interface{}{
+			"return_value": atomic.LoadInt32(&rawLevel),
+		})
+	}
+	httpmock.RegisterResponder("GET", "http://localhost/digital/2", readLevel)
+	httpmock.RegisterResponder("GET", "http://localhost/digital/3", readLevel)
+
+	// Bad kind
+	activeLevel := arest.NewLevel()
+	activeLevel.SetLevelLow()
+	_, err := NewSensor(client, 2, "bad", activeLevel, true)
+	assert.Error(t, err)
+
+	// Door contact with pullup, closed when pin is low
+	sensor, err := NewSensor(client, 2, KindContact, activeLevel, true)
+	assert.NoError(t, err)
+	clock := device.NewFakeClock(time.Now())
+	sensor.(*SensorImp).SetClock(clock)
+	assert.Equal(t, "", sensor.State())
+
+	assert.NoError(t, sensor.Read())
+	assert.Equal(t, StateClosed, sensor.State())
+	assert.Equal(t, true, sensor.IsActive())
+	assert.Equal(t, clock.Now(), sensor.LastChange())
+
+	atomic.StoreInt32(&rawLevel, 1)
+	clock.Add(1 * time.Minute)
+	assert.NoError(t, sensor.Read())
+	assert.Equal(t, StateOpen, sensor.State())
+	assert.Equal(t, false, sensor.IsActive())
+	clock.Add(10 * time.Second)
+	assert.Equal(t, 10*time.Second, sensor.Since())
+
+	// Debounce
+	sensor.(*SensorImp).SetDebouncer(device.NewSampleDebouncer(2))
+	atomic.StoreInt32(&rawLevel, 0)
+	assert.NoError(t, sensor.Read())
+	assert.Equal(t, StateOpen, sensor.State())
+	assert.NoError(t, sensor.Read())
+	assert.Equal(t, StateClosed, sensor.State())
+
+	// Float switch, wet when pin is high
+	activeLevel = arest.NewLevel()
+	activeLevel.SetLevelHigh()
+	sensor, err = NewSensor(client, 3, KindLiquid, activeLevel, false)
+	assert.NoError(t, err)
+	assert.Error(t, sensor.Run(context.Background(), 0))
+	var nbChange int32
+	sensor.OnChange(func(event Event) {
+		atomic.AddInt32(&nbChange, 1)
+	})
+	events := sensor.Events()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		sensor.Run(ctx, 10*time.Millisecond)
+		close(done)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, StateDry, sensor.State())
+	atomic.StoreInt32(&rawLevel, 1)
+	event := <-events
+	assert.Equal(t, StateWet, event.State)
+	assert.Equal(t, true, event.Active)
+	assert.True(t, event.Duration >= 40*time.Millisecond)
+	atomic.StoreInt32(&rawLevel, 0)
+	event = <-events
+	assert.Equal(t, StateDry, event.State)
+
+	cancel()
+	<-done
+	_, ok := <-events
+	assert.False(t, ok)
+	_, ok = <-sensor.Events()
+	assert.False(t, ok)
+	assert.Equal(t, device.ErrAlreadyRun, sensor.Run(ctx, 10*time.Millisecond))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&nbChange))
+}
@@ -0,0 +1,316 @@
+package led
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/disaster37/go-arest"
+	"github.com/pkg/errors"
+)
+
+// DefaultGamma is the gamma correction used when no gamma is provided
+const DefaultGamma float64 = 2.2
+
+// fadeInterval is the time between two brightness updates during fade
+const fadeInterval = 20 * time.Millisecond
+
+// Dimmable is the dimmable led interface. It use analog write on PWM pin
+type Dimmable interface {
+
+	// TurnOn turn on led with the last brightness
+	TurnOn() error
+
+	// TurnOff turn off led. The brightness is kept for the next TurnOn
+	TurnOff() error
+
+	// IsOn return true if led is on
+	IsOn() bool
+
+	// SetBrightness set the brightness in percent (0 - 100)
+	SetBrightness(brightness float64) error
+
+	// Brightness return the current brightness in percent
+	Brightness() float64
+
+	// Fade change the brightness progressively during duration
+	Fade(ctx context.Context, brightness float64, duration time.Duration) error
+
+	// Breathe fade in and fade out the led, repeat times. When repeat is 0, it breathe until context is done
+	Breathe(ctx context.Context, period time.Duration, repeat int) error
+
+	// Reset put the led on desired state. It usefull when board reboot
+	Reset() error
+}
+
+// DimmableImp is the default Dimmable implementation
+// It's safe for concurrent use
+type DimmableImp struct {
+	pin            int
+	client         arest.Arest
+	maxDuty        int
+	gamma          float64
+	brightness     float64
+	lastBrightness float64
+	effect         *runningEffect
+	mutex          sync.Mutex
+}
+
+// NewDimmable return new dimmable led device.
+// Resolution is the board PWM resolution in bits (8 for Arduino Uno, 10 for ESP8266...).
+// Gamma is the gamma correction applied on brightness, 1 for linear. Use DefaultGamma when it's 0.
+func NewDimmable(client arest.Arest, pin int, resolution int, gamma float64, defaultBrightness float64) (Dimmable, error) {
+	if resolution < 1 || resolution > 16 {
+		return nil, errors.Errorf("PWM resolution must be between 1 and 16 bits: %d", resolution)
+	}
+	if gamma < 0 {
+		return nil, errors.Errorf("Gamma must be positive: %f", gamma)
+	}
+	if gamma == 0 {
+		gamma = DefaultGamma
+	}
+	if err := checkBrightness(defaultBrightness); err != nil {
+		return nil, err
+	}
+
+	led := &DimmableImp{
+		pin:            pin,
+		client:         client,
+		maxDuty:        1<<uint(resolution) - 1,
+		gamma:          gamma,
+		brightness:     defaultBrightness,
+		lastBrightness: 100,
+	}
+	if defaultBrightness > 0 {
+		led.lastBrightness = defaultBrightness
+	}
+
+	err := led.Reset()
+	if err != nil {
+		return nil, err
+	}
+
+	return led, nil
+}
+
+// TurnOn turn on led with the last brightness
+func (h *DimmableImp) TurnOn() error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.overrideEffect()
+	return h.write(h.lastBrightness)
+}
+
+// TurnOff turn off led. The brightness is kept for the next TurnOn
+func (h *DimmableImp) TurnOff() error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.overrideEffect()
+	return h.write(0)
+}
+
+// IsOn return true if led is on
+func (h *DimmableImp) IsOn() bool {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return h.brightness > 0
+}
+
+// SetBrightness set the brightness in percent (0 - 100)
+func (h *DimmableImp) SetBrightness(brightness float64) error {
+	if err := checkBrightness(brightness); err != nil {
+		return err
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.overrideEffect()
+	if brightness > 0 {
+		h.lastBrightness = brightness
+	}
+	return h.write(brightness)
+}
+
+// Brightness return the current brightness in percent
+func (h *DimmableImp) Brightness() float64 {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return h.brightness
+}
+
+// Reset put the led on desired state. It usefull when board reboot
+func (h *DimmableImp) Reset() error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.overrideEffect()
+	mode := arest.NewMode()
+	mode.SetModeOutput()
+	err := h.client.SetPinMode(h.pin, mode)
+	if err != nil {
+		return err
+	}
+
+	return h.write(h.brightness)
+}
+
+// Fade change the brightness progressively during duration.
+// It replace the running fade or breathe, and it return ErrEffectOverridden
+// if brightness is changed in the meantime
+func (h *DimmableImp) Fade(ctx context.Context, brightness float64, duration time.Duration) error {
+	if err := checkBrightness(brightness); err != nil {
+		return err
+	}
+
+	ctx, running, from := h.startEffect(ctx)
+	defer running.cancel()
+
+	err := h.animate(ctx, running, duration, func(progress float64) float64 {
+		return from + (brightness-from)*progress
+	})
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if running.overridden {
+		return ErrEffectOverridden
+	}
+	h.effect = nil
+	if err != nil {
+		return err
+	}
+	if brightness > 0 {
+		h.lastBrightness = brightness
+	}
+
+	return nil
+}
+
+// Breathe fade in and fade out the led with last brightness, repeat times.
+// When repeat is 0, it breathe until context is done.
+// Then it restore the brightness
+func (h *DimmableImp) Breathe(ctx context.Context, period time.Duration, repeat int) (err error) {
+	if period <= 0 {
+		return errors.Errorf("Period must be positive: %s", period)
+	}
+	if repeat < 0 {
+		return errors.Errorf("Repeat must be positive: %d", repeat)
+	}
+
+	ctx, running, from := h.startEffect(ctx)
+	defer running.cancel()
+
+	h.mutex.Lock()
+	max := h.lastBrightness
+	h.mutex.Unlock()
+
+	for i := 0; repeat == 0 || i < repeat; i++ {
+		err = h.animate(ctx, running, period, func(progress float64) float64 {
+			return max * (1 - math.Cos(2*math.Pi*progress)) / 2
+		})
+		if err != nil || ctx.Err() != nil {
+			break
+		}
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if running.overridden {
+		return ErrEffectOverridden
+	}
+	h.effect = nil
+	if err != nil && err != ctx.Err() {
+		return err
+	}
+
+	// Restore brightness
+	if errRestore := h.write(from); errRestore != nil {
+		return errRestore
+	}
+
+	return err
+}
+
+// startEffect override the running effect and return the current brightness
+func (h *DimmableImp) startEffect(ctx context.Context) (context.Context, *runningEffect, float64) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.overrideEffect()
+	running := &runningEffect{
+		cancel: cancel,
+	}
+	h.effect = running
+
+	return ctx, running, h.brightness
+}
+
+// animate write brightness computed from progress (0 - 1) until duration is reached
+func (h *DimmableImp) animate(ctx context.Context, running *runningEffect, duration time.Duration, brightness func(progress float64) float64) (err error) {
+	ticker := time.NewTicker(fadeInterval)
+	defer ticker.Stop()
+	start := time.Now()
+
+	for {
+		progress := 1.0
+		if duration > 0 {
+			progress = math.Min(float64(time.Since(start))/float64(duration), 1)
+		}
+
+		h.mutex.Lock()
+		if running.overridden {
+			h.mutex.Unlock()
+			return nil
+		}
+		err = h.write(brightness(progress))
+		h.mutex.Unlock()
+		if err != nil || progress >= 1 {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// write set the PWM duty cycle with gamma correction. Lock must be held
+func (h *DimmableImp) write(brightness float64) error {
+	duty := int(math.Round(math.Pow(brightness/100, h.gamma) * float64(h.maxDuty)))
+	err := h.client.AnalogWrite(h.pin, duty)
+	if err != nil {
+		return err
+	}
+
+	h.brightness = brightness
+	return nil
+}
+
+// overrideEffect cancel the running effect without restore brightness. Lock must be held
+func (h *DimmableImp) overrideEffect() {
+	if h.effect != nil {
+		h.effect.overridden = true
+		h.effect.cancel()
+		h.effect = nil
+	}
+}
+
+func checkBrightness(brightness float64) error {
+	// Negated test, so NaN is rejected too
+	if !(brightness >= 0 && brightness <= 100) {
+		return errors.Errorf("Brightness must be between 0 and 100: %f", brightness)
+	}
+
+	return nil
+}
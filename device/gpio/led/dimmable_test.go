@@ -0,0 +1,134 @@
+package led
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/disaster37/go-arest/rest"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDimmable(t *testing.T) {
+	client := rest.MockRestClient()
+	duties := make([]int, 0)
+	mutex := &sync.Mutex{}
+	httpmock.RegisterResponder("POST", "http://localhost/mode/5/o", httpmock.NewStringResponder(200, `{}`))
+	httpmock.RegisterResponder("POST", `=~^http://localhost/analog/5/\d+\z`, func(req *http.Request) (*http.Response, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		duty, _ := strconv.Atoi(req.URL.Path[strings.LastIndex(req.URL.Path, "/")+1:])
+		duties = append(duties, duty)
+		return httpmock.NewStringResponse(200, `{}`), nil
+	})
+	lastDuty := func() int {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return duties[len(duties)-1]
+	}
+
+	// Bad parameters
+	_, err := NewDimmable(client, 5, 0, 1, 0)
+	assert.Error(t, err)
+	_, err = NewDimmable(client, 5, 8, -1, 0)
+	assert.Error(t, err)
+	_, err = NewDimmable(client, 5, 8, 1, 120)
+	assert.Error(t, err)
+
+	// Linear on 8 bits
+	led, err := NewDimmable(client, 5, 8, 1, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, lastDuty())
+	assert.Equal(t, false, led.IsOn())
+
+	assert.NoError(t, led.SetBrightness(50))
+	assert.Equal(t, 128, lastDuty())
+	assert.Equal(t, true, led.IsOn())
+	assert.Error(t, led.SetBrightness(101))
+	assert.Error(t, led.SetBrightness(math.NaN()))
+
+	// Turn off and on restore the last brightness
+	assert.NoError(t, led.TurnOff())
+	assert.Equal(t, 0, lastDuty())
+	assert.Equal(t, float64(0), led.Brightness())
+	assert.NoError(t, led.TurnOn())
+	assert.Equal(t, 128, lastDuty())
+	assert.Equal(t, float64(50), led.Brightness())
+
+	// Gamma correction on 10 bits
+	led, err = NewDimmable(client, 5, 10, 0, 50)
+	assert.NoError(t, err)
+	assert.Equal(t, 223, lastDuty())
+	assert.NoError(t, led.SetBrightness(100))
+	assert.Equal(t, 1023, lastDuty())
+
+	// Fade out, then turn on restore the brightness before fade
+	led, err = NewDimmable(client, 5, 8, 1, 80)
+	assert.NoError(t, err)
+	mutex.Lock()
+	duties = duties[:0]
+	mutex.Unlock()
+	err = led.Fade(context.Background(), 0, 100*time.Millisecond)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, lastDuty())
+	mutex.Lock()
+	assert.True(t, len(duties) > 2)
+	for i := 1; i < len(duties); i++ {
+		assert.True(t, duties[i] <= duties[i-1])
+	}
+	mutex.Unlock()
+	assert.NoError(t, led.TurnOn())
+	assert.Equal(t, 204, lastDuty())
+
+	// Fade in
+	assert.NoError(t, led.TurnOff())
+	err = led.Fade(context.Background(), 40, 50*time.Millisecond)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(40), led.Brightness())
+
+	// Breathe and restore brightness
+	err = led.Breathe(context.Background(), 60*time.Millisecond, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(40), led.Brightness())
+	assert.Equal(t, 102, lastDuty())
+
+	// Bad period
+	assert.Error(t, led.Breathe(context.Background(), 0, 0))
+	assert.Error(t, led.Breathe(context.Background(), -1*time.Second, 1))
+
+	// Breathe until context is done
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err = led.Breathe(ctx, 60*time.Millisecond, 0)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Equal(t, float64(40), led.Brightness())
+
+	// Set brightness override fade
+	errFade := make(chan error)
+	go func() {
+		errFade <- led.Fade(context.Background(), 100, 1*time.Second)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	assert.NoError(t, led.SetBrightness(10))
+	assert.Equal(t, ErrEffectOverridden, <-errFade)
+	assert.Equal(t, float64(10), led.Brightness())
+
+	// Breathe is replaced by fade
+	errBreathe := make(chan error)
+	go func() {
+		errBreathe <- led.Breathe(context.Background(), 100*time.Millisecond, 0)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	go func() {
+		errFade <- led.Fade(context.Background(), 60, 50*time.Millisecond)
+	}()
+	assert.Equal(t, ErrEffectOverridden, <-errBreathe)
+	assert.NoError(t, <-errFade)
+	assert.Equal(t, float64(60), led.Brightness())
+}
@@ -0,0 +1,132 @@
+package led
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Step is the led state during a duration
+type Step struct {
+	On       bool
+	Duration time.Duration
+}
+
+// Effect is a sequence of steps played Repeat times
+// When Repeat is 0, the effect is played until it's canceled
+type Effect struct {
+	Steps  []Step
+	Repeat int
+}
+
+var morseCode = map[rune]string{
+	'A': ".-", 'B': "-...", 'C': "-.-.", 'D': "-..", 'E': ".", 'F': "..-.",
+	'G': "--.", 'H': "....", 'I': "..", 'J': ".---", 'K': "-.-", 'L': ".-..",
+	'M': "--", 'N': "-.", 'O': "---", 'P': ".--.", 'Q': "--.-", 'R': ".-.",
+	'S': "...", 'T': "-", 'U': "..-", 'V': "...-", 'W': ".--", 'X': "-..-",
+	'Y': "-.--", 'Z': "--..",
+	'0': "-----", '1': ".----", '2': "..---", '3': "...--", '4': "....-",
+	'5': ".....", '6': "-....", '7': "--...", '8': "---..", '9': "----.",
+}
+
+// BlinkEffect return effect that turn on the led during on and turn off it during off
+func BlinkEffect(on time.Duration, off time.Duration, repeat int) *Effect {
+	return &Effect{
+		Steps: []Step{
+			{On: true, Duration: on},
+			{On: false, Duration: off},
+		},
+		Repeat: repeat,
+	}
+}
+
+// FlashEffect return effect that flash the led n times, then wait pause before the next repeat
+func FlashEffect(n int, on time.Duration, off time.Duration, pause time.Duration, repeat int) *Effect {
+	steps := make([]Step, 0, n*2)
+	for i := 0; i < n; i++ {
+		steps = append(steps, Step{On: true, Duration: on}, Step{On: false, Duration: off})
+	}
+	if n > 0 {
+		steps[len(steps)-1].Duration += pause
+	}
+
+	return &Effect{
+		Steps:  steps,
+		Repeat: repeat,
+	}
+}
+
+// HeartbeatEffect return effect that double flash the led like heart beat
+func HeartbeatEffect(repeat int) *Effect {
+	return FlashEffect(2, 100*time.Millisecond, 100*time.Millisecond, 600*time.Millisecond, repeat)
+}
+
+// MorseEffect return effect that play the text in morse code.
+// The unit is the dot duration, dash is 3 units, gap between letters is 3 units
+// and gap between words is 7 units
+func MorseEffect(text string, unit time.Duration, repeat int) (*Effect, error) {
+	steps := make([]Step, 0)
+
+	for _, word := range strings.Fields(strings.ToUpper(text)) {
+		for _, letter := range word {
+			code, ok := morseCode[letter]
+			if !ok {
+				return nil, errors.Errorf("Character %q is not supported in morse code", letter)
+			}
+			for _, symbol := range code {
+				duration := unit
+				if symbol == '-' {
+					duration = 3 * unit
+				}
+				steps = append(steps, Step{On: true, Duration: duration}, Step{On: false, Duration: unit})
+			}
+			// Gap between letters
+			steps[len(steps)-1].Duration = 3 * unit
+		}
+		// Gap between words
+		steps[len(steps)-1].Duration = 7 * unit
+	}
+
+	if len(steps) == 0 {
+		return nil, errors.New("Text is empty")
+	}
+
+	return &Effect{
+		Steps:  steps,
+		Repeat: repeat,
+	}, nil
+}
+
+// SOSEffect return effect that play SOS in morse code
+func SOSEffect(unit time.Duration, repeat int) *Effect {
+	effect, _ := MorseEffect("SOS", unit, repeat)
+	return effect
+}
+
+// Duration return the duration of one play of the effect
+func (e *Effect) Duration() time.Duration {
+	var duration time.Duration
+	for _, step := range e.Steps {
+		duration += step.Duration
+	}
+
+	return duration
+}
+
+// validate check the effect can be played
+func (e *Effect) validate() error {
+	if e == nil || len(e.Steps) == 0 {
+		return errors.New("Effect has no step")
+	}
+	if e.Repeat < 0 {
+		return errors.Errorf("Repeat must be positive: %d", e.Repeat)
+	}
+	for i, step := range e.Steps {
+		if step.Duration <= 0 {
+			return errors.Errorf("Step %d must have positive duration", i)
+		}
+	}
+
+	return nil
+}
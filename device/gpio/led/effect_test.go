@@ -0,0 +1,56 @@
+package led
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEffect(t *testing.T) {
+
+	// Blink
+	effect := BlinkEffect(200*time.Millisecond, 100*time.Millisecond, 3)
+	assert.NoError(t, effect.validate())
+	assert.Equal(t, []Step{{On: true, Duration: 200 * time.Millisecond}, {On: false, Duration: 100 * time.Millisecond}}, effect.Steps)
+	assert.Equal(t, 3, effect.Repeat)
+	assert.Equal(t, 300*time.Millisecond, effect.Duration())
+
+	// Flash
+	effect = FlashEffect(3, 10*time.Millisecond, 20*time.Millisecond, 100*time.Millisecond, 0)
+	assert.NoError(t, effect.validate())
+	assert.Len(t, effect.Steps, 6)
+	assert.Equal(t, 190*time.Millisecond, effect.Duration())
+
+	// Heartbeat
+	effect = HeartbeatEffect(1)
+	assert.NoError(t, effect.validate())
+	assert.Len(t, effect.Steps, 4)
+	assert.Equal(t, 1*time.Second, effect.Duration())
+
+	// Morse
+	effect, err := MorseEffect("e t", 10*time.Millisecond, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []Step{
+		{On: true, Duration: 10 * time.Millisecond},
+		{On: false, Duration: 70 * time.Millisecond},
+		{On: true, Duration: 30 * time.Millisecond},
+		{On: false, Duration: 70 * time.Millisecond},
+	}, effect.Steps)
+
+	// SOS
+	effect = SOSEffect(10*time.Millisecond, 0)
+	assert.NoError(t, effect.validate())
+	assert.Len(t, effect.Steps, 18)
+	assert.Equal(t, 30*time.Millisecond, effect.Steps[5].Duration)
+	assert.Equal(t, 30*time.Millisecond, effect.Steps[6].Duration)
+
+	// Bad effects
+	_, err = MorseEffect("é", 10*time.Millisecond, 1)
+	assert.Error(t, err)
+	_, err = MorseEffect(" ", 10*time.Millisecond, 1)
+	assert.Error(t, err)
+	assert.Error(t, (&Effect{}).validate())
+	assert.Error(t, BlinkEffect(0, 10*time.Millisecond, 1).validate())
+	assert.Error(t, BlinkEffect(10*time.Millisecond, 10*time.Millisecond, -1).validate())
+}
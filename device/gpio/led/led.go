@@ -1,12 +1,19 @@
 package led
 
 import (
+	"context"
+	"sync"
 	"time"
 
 	"github.com/disaster37/go-arest"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 )
 
+// ErrEffectOverridden is returned by Play when other effect is started
+// or when led is turned on / off during the effect
+var ErrEffectOverridden = errors.New("Effect overridden")
+
 // Led is the led interface
 type Led interface {
 	TurnOn() error
@@ -14,13 +21,31 @@ type Led interface {
 	Blink(duration time.Duration) *time.Timer
 	Toogle() error
 	Reset() error
+	IsOn() bool
+
+	// Play run the effect until it's finished or context is done, then restore the led state
+	// It replace the running effect
+	Play(ctx context.Context, effect *Effect) error
+
+	// Stop cancel the running effect
+	Stop()
 }
 
 // LedImp is the default Led implementation
+// It's safe for concurrent use
 type LedImp struct {
 	pin    int
 	client arest.Arest
 	state  bool
+	effect *runningEffect
+	mutex  sync.Mutex
+}
+
+// runningEffect is the effect currently played
+type runningEffect struct {
+	cancel     context.CancelFunc
+	baseState  bool
+	overridden bool
 }
 
 // NewLed return new led device
@@ -42,6 +67,31 @@ func NewLed(client arest.Arest, pin int, defaultState bool) (Led, error) {
 
 // TurnOn turn on led
 func (h *LedImp) TurnOn() error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.overrideEffect()
+	return h.turnOn()
+}
+
+// TurnOff turn on led
+func (h *LedImp) TurnOff() error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.overrideEffect()
+	return h.turnOff()
+}
+
+// IsOn return true if led is on
+func (h *LedImp) IsOn() bool {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return h.state
+}
+
+func (h *LedImp) turnOn() error {
 	level := arest.NewLevel()
 	level.SetLevelHigh()
 	err := h.client.DigitalWrite(h.pin, level)
@@ -53,8 +103,7 @@ func (h *LedImp) TurnOn() error {
 	return nil
 }
 
-// TurnOff turn on led
-func (h *LedImp) TurnOff() error {
+func (h *LedImp) turnOff() error {
 	level := arest.NewLevel()
 	level.SetLevelLow()
 	err := h.client.DigitalWrite(h.pin, level)
@@ -68,6 +117,10 @@ func (h *LedImp) TurnOff() error {
 
 // Toogle the led state
 func (h *LedImp) Toogle() error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.overrideEffect()
 	level := arest.NewLevel()
 	if h.state {
 		level.SetLevelLow()
@@ -85,7 +138,10 @@ func (h *LedImp) Toogle() error {
 
 // Reset put the led on desired state
 func (h *LedImp) Reset() error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
 
+	h.overrideEffect()
 	mode := arest.NewMode()
 	mode.SetModeOutput()
 	err := h.client.SetPinMode(h.pin, mode)
@@ -94,43 +150,130 @@ func (h *LedImp) Reset() error {
 	}
 
 	if h.state {
-		return h.TurnOn()
+		return h.turnOn()
 	}
 
-	return h.TurnOff()
+	return h.turnOff()
 }
 
 // Blink the led during time
+// The returned timer expire when blink is finished
+//
+// Deprecated: use Play with BlinkEffect
 func (h *LedImp) Blink(duration time.Duration) *time.Timer {
-
 	timer := time.NewTimer(duration)
+	ctx, cancel := context.WithTimeout(context.Background(), duration)
 
 	go func() {
-		expectedState := h.state
-		for {
+		defer cancel()
+		err := h.Play(ctx, BlinkEffect(1*time.Second, 1*time.Second, 0))
+		if err != nil && err != context.DeadlineExceeded && err != ErrEffectOverridden {
+			log.Errorf("Error appear when blink led: %s", err.Error())
+		}
+	}()
+
+	return timer
+}
+
+// Play run the effect until it's finished or context is done, then restore the led state.
+// It replace the running effect.
+// It return ErrEffectOverridden if other effect is started or if led is turned on / off in the meantime
+func (h *LedImp) Play(ctx context.Context, effect *Effect) (err error) {
+	if err = effect.validate(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	h.mutex.Lock()
+	running := &runningEffect{
+		cancel:    cancel,
+		baseState: h.state,
+	}
+	if h.effect != nil {
+		// Keep the state before the first effect
+		running.baseState = h.effect.baseState
+		h.overrideEffect()
+	}
+	h.effect = running
+	h.mutex.Unlock()
+
+	err = h.play(ctx, running, effect)
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if running.overridden {
+		return ErrEffectOverridden
+	}
+	h.effect = nil
+	if err != nil && err != ctx.Err() {
+		return err
+	}
+
+	// Restore led state
+	if running.baseState {
+		if errRestore := h.turnOn(); errRestore != nil {
+			return errRestore
+		}
+	} else {
+		if errRestore := h.turnOff(); errRestore != nil {
+			return errRestore
+		}
+	}
+
+	return err
+}
+
+// Stop cancel the running effect
+// The led state before effect is restored
+func (h *LedImp) Stop() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.effect != nil {
+		h.effect.cancel()
+	}
+}
+
+// play apply each step of the effect
+func (h *LedImp) play(ctx context.Context, running *runningEffect, effect *Effect) (err error) {
+	for i := 0; effect.Repeat == 0 || i < effect.Repeat; i++ {
+		for _, step := range effect.Steps {
+			h.mutex.Lock()
+			if running.overridden {
+				h.mutex.Unlock()
+				return nil
+			}
+			if step.On {
+				err = h.turnOn()
+			} else {
+				err = h.turnOff()
+			}
+			h.mutex.Unlock()
+			if err != nil {
+				return err
+			}
+
+			timer := time.NewTimer(step.Duration)
 			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
 			case <-timer.C:
-				if expectedState {
-					err := h.TurnOn()
-					if err != nil {
-						log.Errorf("Error appear when turn on led: %s", err.Error())
-					}
-				} else {
-					err := h.TurnOff()
-					if err != nil {
-						log.Errorf("Error appear when turn off led: %s", err.Error())
-					}
-				}
-				return
-			default:
-				err := h.Toogle()
-				if err != nil {
-					log.Errorf("Error appear when toogle led: %s", err.Error())
-				}
-				time.Sleep(1 * time.Second)
 			}
 		}
-	}()
+	}
 
-	return timer
+	return nil
+}
+
+// overrideEffect cancel the running effect without restore led state. Lock must be held
+func (h *LedImp) overrideEffect() {
+	if h.effect != nil {
+		h.effect.overridden = true
+		h.effect.cancel()
+		h.effect = nil
+	}
 }
@@ -1,6 +1,9 @@
 package led
 
 import (
+	"context"
+	"net/http"
+	"sync"
 	"testing"
 	"time"
 
@@ -31,29 +34,133 @@ func TestLed(t *testing.T) {
 	// Turn on
 	err = led.TurnOn()
 	assert.NoError(t, err)
-	assert.Equal(t, true, led.(*LedImp).state)
+	assert.Equal(t, true, led.IsOn())
 
 	// Turn off
 	err = led.TurnOff()
 	assert.NoError(t, err)
-	assert.Equal(t, false, led.(*LedImp).state)
+	assert.Equal(t, false, led.IsOn())
 
 	// Toogle
 	led.TurnOff()
 	err = led.Toogle()
 	assert.NoError(t, err)
-	assert.Equal(t, true, led.(*LedImp).state)
+	assert.Equal(t, true, led.IsOn())
 
 	// Blink
 	led.TurnOff()
 	timer := led.Blink(1 * time.Second)
 	<-timer.C
 	time.Sleep(5 * time.Second)
-	assert.Equal(t, false, led.(*LedImp).state)
+	assert.Equal(t, false, led.IsOn())
 
 	// Reset
 	led.TurnOn()
 	err = led.Reset()
 	assert.NoError(t, err)
-	assert.Equal(t, true, led.(*LedImp).state)
+	assert.Equal(t, true, led.IsOn())
+}
+
+func TestLedConcurrent(t *testing.T) {
+	client := rest.MockRestClient()
+	responder := httpmock.NewStringResponder(200, `{}`)
+	httpmock.RegisterResponder("POST", "http://localhost/mode/1/o", responder)
+	httpmock.RegisterResponder("POST", "http://localhost/digital/1/1", responder)
+	httpmock.RegisterResponder("POST", "http://localhost/digital/1/0", responder)
+
+	led, err := NewLed(client, 1, false)
+	assert.NoError(t, err)
+
+	// Blink and turn on / off at the same time
+	led.Blink(100 * time.Millisecond)
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				assert.NoError(t, led.TurnOn())
+				assert.NoError(t, led.Toogle())
+				assert.NoError(t, led.TurnOff())
+				led.IsOn()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.NoError(t, led.TurnOn())
+	assert.Equal(t, true, led.IsOn())
+}
+
+func TestLedPlay(t *testing.T) {
+	client := rest.MockRestClient()
+	writes := make([]string, 0)
+	mutex := &sync.Mutex{}
+	recorder := func(req *http.Request) (*http.Response, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		writes = append(writes, req.URL.Path)
+		return httpmock.NewStringResponse(200, `{}`), nil
+	}
+	httpmock.RegisterResponder("POST", "http://localhost/mode/2/o", recorder)
+	httpmock.RegisterResponder("POST", "http://localhost/digital/2/1", recorder)
+	httpmock.RegisterResponder("POST", "http://localhost/digital/2/0", recorder)
+	nbWrites := func() int {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return len(writes)
+	}
+
+	led, err := NewLed(client, 2, false)
+	assert.NoError(t, err)
+
+	// Play effect until the end and restore state
+	writes = writes[:0]
+	err = led.Play(context.Background(), BlinkEffect(10*time.Millisecond, 10*time.Millisecond, 2))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/digital/2/1", "/digital/2/0", "/digital/2/1", "/digital/2/0", "/digital/2/0"}, writes)
+	assert.Equal(t, false, led.IsOn())
+
+	// Bad effect
+	err = led.Play(context.Background(), &Effect{})
+	assert.Error(t, err)
+
+	// Cancel with context
+	led.TurnOn()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = led.Play(ctx, BlinkEffect(10*time.Millisecond, 10*time.Millisecond, 0))
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Equal(t, true, led.IsOn())
+
+	// New effect replace the running one and keep the state before the first effect
+	led.TurnOff()
+	errFirst := make(chan error)
+	go func() {
+		errFirst <- led.Play(context.Background(), SOSEffect(10*time.Millisecond, 0))
+	}()
+	time.Sleep(20 * time.Millisecond)
+	errSecond := make(chan error)
+	go func() {
+		errSecond <- led.Play(context.Background(), HeartbeatEffect(0))
+	}()
+	assert.Equal(t, ErrEffectOverridden, <-errFirst)
+
+	// Stop the effect
+	time.Sleep(20 * time.Millisecond)
+	led.Stop()
+	assert.Equal(t, context.Canceled, <-errSecond)
+	assert.Equal(t, false, led.IsOn())
+
+	// Turn on override the effect
+	go func() {
+		errFirst <- led.Play(context.Background(), BlinkEffect(10*time.Millisecond, 10*time.Millisecond, 0))
+	}()
+	time.Sleep(20 * time.Millisecond)
+	assert.NoError(t, led.TurnOn())
+	assert.Equal(t, ErrEffectOverridden, <-errFirst)
+	count := nbWrites()
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, count, nbWrites())
+	assert.Equal(t, true, led.IsOn())
 }
@@ -0,0 +1,223 @@
+package motion
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/disaster37/go-arest"
+	"github.com/disaster37/go-arest/device"
+	"github.com/pkg/errors"
+)
+
+// Motion event types
+const (
+	EventOccupied string = "occupied"
+	EventVacant   string = "vacant"
+)
+
+// Event is emitted when occupancy change
+type Event struct {
+	Type string
+	Time time.Time
+}
+
+// Sensor is PIR motion sensor interface
+type Sensor interface {
+
+	// Read read the pin and compute the occupancy
+	Read() error
+
+	// IsOccupied return true if motion is detected since less than the hold-off
+	IsOccupied() bool
+
+	// IsWarmedUp return true when the warm-up period is elapsed
+	IsWarmedUp() bool
+
+	// LastMotion return the time of the last motion detected
+	LastMotion() time.Time
+
+	// OnOccupied add handler called by Run when area become occupied
+	OnOccupied(handler func())
+
+	// OnVacant add handler called by Run when area become vacant
+	OnVacant(handler func())
+
+	// Events return the channel where events are sent
+	// It's closed when Run return
+	Events() <-chan Event
+
+	// Run poll the sensor until context is done
+	// It can be run only once, next calls return device.ErrAlreadyRun
+	Run(ctx context.Context, interval time.Duration) (err error)
+}
+
+// SensorImp is the default Sensor implementation
+// It's safe for concurrent use
+type SensorImp struct {
+	pin        int
+	client     arest.Arest
+	signal     arest.Level
+	holdOff    time.Duration
+	warmUp     time.Duration
+	clock      device.Clock
+	start      time.Time
+	occupied   bool
+	lastMotion time.Time
+	poller     *device.Poller
+	emitter    *device.Emitter[Event]
+	mutex      sync.Mutex
+}
+
+// NewSensor return new PIR motion sensor.
+// Signal is the pin level when motion is detected.
+// Area stay occupied until no motion is detected during hold-off,
+// and readings are ignored during warm-up after sensor creation
+func NewSensor(client arest.Arest, pin int, signal arest.Level, holdOff time.Duration, warmUp time.Duration) (Sensor, error) {
+	mode := arest.NewMode()
+	mode.SetModeInput()
+
+	err := client.SetPinMode(pin, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	clock := device.NewClock()
+
+	return &SensorImp{
+		pin:     pin,
+		client:  client,
+		signal:  signal,
+		holdOff: holdOff,
+		warmUp:  warmUp,
+		clock:   clock,
+		start:   clock.Now(),
+		poller:  device.NewPoller(),
+		emitter: device.NewEmitter[Event](),
+	}, nil
+}
+
+// SetClock permit to use custom clock
+// The warm-up period restart from the clock time
+func (h *SensorImp) SetClock(clock device.Clock) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.clock = clock
+	h.start = clock.Now()
+}
+
+// Read read the pin and compute the occupancy
+func (h *SensorImp) Read() error {
+	_, err := h.read()
+	return err
+}
+
+// IsOccupied return true if motion is detected since less than the hold-off
+func (h *SensorImp) IsOccupied() bool {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return h.occupied
+}
+
+// IsWarmedUp return true when the warm-up period is elapsed
+func (h *SensorImp) IsWarmedUp() bool {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return h.isWarmedUp(h.clock.Now())
+}
+
+// LastMotion return the time of the last motion detected
+func (h *SensorImp) LastMotion() time.Time {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return h.lastMotion
+}
+
+// OnOccupied add handler called by Run when area become occupied
+func (h *SensorImp) OnOccupied(handler func()) {
+	h.onEvent(EventOccupied, handler)
+}
+
+// OnVacant add handler called by Run when area become vacant
+func (h *SensorImp) OnVacant(handler func()) {
+	h.onEvent(EventVacant, handler)
+}
+
+// Events return the channel where events are sent
+// It's closed when Run return
+func (h *SensorImp) Events() <-chan Event {
+	return h.emitter.Events()
+}
+
+// Run poll the sensor until context is done
+// It can be run only once, next calls return device.ErrAlreadyRun
+func (h *SensorImp) Run(ctx context.Context, interval time.Duration) (err error) {
+	err = h.poller.Run(ctx, interval, func() error {
+		event, err := h.read()
+		if err != nil {
+			return errors.Wrapf(err, "Error when read motion sensor on pin %d", h.pin)
+		}
+		if event != nil {
+			h.emitter.Emit(ctx, *event)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	h.emitter.Close()
+
+	return nil
+}
+
+// onEvent add handler called on events of type
+func (h *SensorImp) onEvent(eventType string, handler func()) {
+	h.emitter.OnEvent(func(event Event) {
+		if event.Type == eventType {
+			handler()
+		}
+	})
+}
+
+// read read the pin and return event if occupancy change
+func (h *SensorImp) read() (*Event, error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	// Sensor output is not reliable during warm-up
+	now := h.clock.Now()
+	if !h.isWarmedUp(now) {
+		return nil, nil
+	}
+
+	level, err := h.client.DigitalRead(h.pin)
+	if err != nil {
+		return nil, err
+	}
+
+	if level.IsHigh() == h.signal.IsHigh() {
+		h.lastMotion = now
+		if !h.occupied {
+			h.occupied = true
+			return &Event{Type: EventOccupied, Time: now}, nil
+		}
+		return nil, nil
+	}
+
+	if h.occupied && now.Sub(h.lastMotion) >= h.holdOff {
+		h.occupied = false
+		return &Event{Type: EventVacant, Time: now}, nil
+	}
+
+	return nil, nil
+}
+
+// isWarmedUp return true when warm-up is elapsed. Lock must be held
+func (h *SensorImp) isWarmedUp(now time.Time) bool {
+	return now.Sub(h.start) >= h.warmUp
+}
@@ -0,0 +1,103 @@
+package motion
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/disaster37/go-arest"
+	"github.com/disaster37/go-arest/device"
+	"github.com/disaster37/go-arest/rest"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSensor(t *testing.T) {
+	client := rest.MockRestClient()
+	signal := arest.NewLevel()
+	signal.SetLevelHigh()
+	var rawLevel, nbRead int32
+	httpmock.RegisterResponder("POST", "http://localhost/mode/5/i", httpmock.NewStringResponder(200, `{}`))
+	httpmock.RegisterResponder("GET", "http://localhost/digital/5", func(req *http.Request) (*http.Response, error) {
+		atomic.AddInt32(&nbRead, 1)
+		return httpmock.NewJsonResponse(200, map[string]interface{}{
+			"return_value": atomic.LoadInt32(&rawLevel),
+		})
+	})
+
+	sensor, err := NewSensor(client, 5, signal, 1*time.Minute, 30*time.Second)
+	assert.NoError(t, err)
+	clock := device.NewFakeClock(time.Now())
+	sensor.(*SensorImp).SetClock(clock)
+
+	// Warm-up
+	atomic.StoreInt32(&rawLevel, 1)
+	assert.NoError(t, sensor.Read())
+	assert.Equal(t, false, sensor.IsWarmedUp())
+	assert.Equal(t, false, sensor.IsOccupied())
+	assert.Equal(t, int32(0), atomic.LoadInt32(&nbRead))
+
+	// Motion
+	clock.Add(30 * time.Second)
+	assert.NoError(t, sensor.Read())
+	assert.Equal(t, true, sensor.IsWarmedUp())
+	assert.Equal(t, true, sensor.IsOccupied())
+	assert.Equal(t, clock.Now(), sensor.LastMotion())
+
+	// Hold-off is extended by new motion
+	atomic.StoreInt32(&rawLevel, 0)
+	clock.Add(50 * time.Second)
+	assert.NoError(t, sensor.Read())
+	assert.Equal(t, true, sensor.IsOccupied())
+	atomic.StoreInt32(&rawLevel, 1)
+	assert.NoError(t, sensor.Read())
+	atomic.StoreInt32(&rawLevel, 0)
+	clock.Add(50 * time.Second)
+	assert.NoError(t, sensor.Read())
+	assert.Equal(t, true, sensor.IsOccupied())
+
+	// Vacant after hold-off
+	clock.Add(10 * time.Second)
+	assert.NoError(t, sensor.Read())
+	assert.Equal(t, false, sensor.IsOccupied())
+
+	// Events
+	sensor, err = NewSensor(client, 5, signal, 50*time.Millisecond, 0)
+	assert.NoError(t, err)
+	assert.Error(t, sensor.Run(context.Background(), 0))
+	var nbOccupied, nbVacant int32
+	sensor.OnOccupied(func() {
+		atomic.AddInt32(&nbOccupied, 1)
+	})
+	sensor.OnVacant(func() {
+		atomic.AddInt32(&nbVacant, 1)
+	})
+	events := sensor.Events()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		sensor.Run(ctx, 10*time.Millisecond)
+		close(done)
+	}()
+
+	atomic.StoreInt32(&rawLevel, 1)
+	event := <-events
+	assert.Equal(t, EventOccupied, event.Type)
+	atomic.StoreInt32(&rawLevel, 0)
+	start := time.Now()
+	event = <-events
+	assert.Equal(t, EventVacant, event.Type)
+	assert.True(t, time.Since(start) >= 40*time.Millisecond)
+
+	cancel()
+	<-done
+	_, ok := <-events
+	assert.False(t, ok)
+	_, ok = <-sensor.Events()
+	assert.False(t, ok)
+	assert.Equal(t, device.ErrAlreadyRun, sensor.Run(ctx, 10*time.Millisecond))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&nbOccupied))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&nbVacant))
+}
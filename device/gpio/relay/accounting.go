@@ -0,0 +1,156 @@
+package relay
+
+import (
+	"time"
+
+	"github.com/disaster37/go-arest/device"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// Counters is the relay runtime accounting
+type Counters struct {
+	// OnTime is the cumulative time when output is on
+	OnTime time.Duration `json:"on_time"`
+
+	// Switches is the number of output changes
+	Switches int64 `json:"switches"`
+
+	// LastChange is the time of the last output change
+	LastChange time.Time `json:"last_change"`
+
+	// Energy is the estimated energy consumed by the load, in watt-hour
+	Energy float64 `json:"energy"`
+}
+
+// accounting keep the relay counters
+type accounting struct {
+	store    device.Store
+	key      string
+	watts    float64
+	counters Counters
+	onSince  time.Time
+}
+
+// SetAccounting enable the runtime accounting, with load power in watt to estimate energy.
+// When store is not nil, counters are loaded from key and saved on each change
+func (r *RelayImp) SetAccounting(store device.Store, key string, watts float64) (err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	a := &accounting{
+		store: store,
+		key:   key,
+		watts: watts,
+	}
+	if store != nil {
+		if _, err = store.Load(key, &a.counters); err != nil {
+			return errors.Wrapf(err, "Error when load counters of relay %s", key)
+		}
+	}
+	if r.outputState.IsOn() {
+		a.onSince = r.clock.Now()
+	}
+	r.accounting = a
+
+	return nil
+}
+
+// SetClock permit to use custom clock for accounting
+func (r *RelayImp) SetClock(clock device.Clock) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.clock = clock
+}
+
+// Counters return the runtime counters, including the current on period
+func (r *RelayImp) Counters() (counters Counters) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.accounting == nil {
+		return counters
+	}
+
+	counters = r.accounting.counters
+	if !r.accounting.onSince.IsZero() {
+		onTime := r.clock.Now().Sub(r.accounting.onSince)
+		counters.OnTime += onTime
+		counters.Energy += r.accounting.watts * onTime.Hours()
+	}
+
+	return counters
+}
+
+// SaveCounters persist the counters, including the current on period.
+// It's usefull to call it periodically when relay stay on for long time
+func (r *RelayImp) SaveCounters() (err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.accounting == nil {
+		return nil
+	}
+	r.checkpoint(r.clock.Now())
+
+	return r.saveCounters()
+}
+
+// ResetCounters set all counters to zero. It's usefull after load maintenance
+func (r *RelayImp) ResetCounters() (err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.accounting == nil {
+		return nil
+	}
+	r.accounting.counters = Counters{}
+	if !r.accounting.onSince.IsZero() {
+		r.accounting.onSince = r.clock.Now()
+	}
+
+	return r.saveCounters()
+}
+
+// account update counters before the output change. Lock must be held
+func (r *RelayImp) account(isOn bool) {
+	if r.accounting == nil || r.outputState.IsOn() == isOn {
+		return
+	}
+
+	now := r.clock.Now()
+	r.checkpoint(now)
+	if isOn {
+		r.accounting.onSince = now
+	} else {
+		r.accounting.onSince = time.Time{}
+	}
+	r.accounting.counters.Switches++
+	r.accounting.counters.LastChange = now
+
+	if err := r.saveCounters(); err != nil {
+		log.Errorf("Error appear when save counters of relay %s: %s", r.accounting.key, err.Error())
+	}
+}
+
+// checkpoint add the current on period to counters. Lock must be held
+func (r *RelayImp) checkpoint(now time.Time) {
+	if r.accounting.onSince.IsZero() {
+		return
+	}
+
+	onTime := now.Sub(r.accounting.onSince)
+	r.accounting.counters.OnTime += onTime
+	r.accounting.counters.Energy += r.accounting.watts * onTime.Hours()
+	r.accounting.onSince = now
+}
+
+// saveCounters persist the counters. Lock must be held
+func (r *RelayImp) saveCounters() (err error) {
+	if r.accounting.store == nil {
+		return nil
+	}
+
+	return r.accounting.store.Save(r.accounting.key, r.accounting.counters)
+}
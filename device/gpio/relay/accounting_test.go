@@ -0,0 +1,99 @@
+package relay
+
+import (
+	"testing"
+	"time"
+
+	"github.com/disaster37/go-arest"
+	"github.com/disaster37/go-arest/device"
+	"github.com/disaster37/go-arest/rest"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRelayAccounting(t *testing.T) {
+	client := rest.MockRestClient()
+	responder := httpmock.NewStringResponder(200, `{}`)
+	for _, url := range []string{"/mode/8/o", "/digital/8/1", "/digital/8/0"} {
+		httpmock.RegisterResponder("POST", "http://localhost"+url, responder)
+	}
+
+	newRelay := func() *RelayImp {
+		signal := arest.NewLevel()
+		signal.SetLevelHigh()
+		output := NewOutput()
+		output.SetOutputNO()
+		defaultState := NewState()
+		defaultState.SetStateOff()
+		relay, err := NewRelay(client, 8, signal, output, defaultState)
+		assert.NoError(t, err)
+		return relay.(*RelayImp)
+	}
+
+	store := device.NewMemoryStore()
+	clock := device.NewFakeClock(time.Now())
+	relay := newRelay()
+	relay.SetClock(clock)
+
+	// Accounting is disabled
+	assert.Equal(t, Counters{}, relay.Counters())
+	assert.NoError(t, relay.SaveCounters())
+
+	assert.NoError(t, relay.SetAccounting(store, "pump", 1000))
+	assert.Equal(t, Counters{}, relay.Counters())
+
+	// On period
+	assert.NoError(t, relay.On())
+	start := clock.Now()
+	clock.Add(30 * time.Minute)
+	counters := relay.Counters()
+	assert.Equal(t, 30*time.Minute, counters.OnTime)
+	assert.Equal(t, int64(1), counters.Switches)
+	assert.Equal(t, start, counters.LastChange)
+	assert.InDelta(t, 500, counters.Energy, 0.001)
+
+	// Same state is not a switch
+	assert.NoError(t, relay.On())
+	assert.NoError(t, relay.Reset())
+	assert.Equal(t, int64(1), relay.Counters().Switches)
+
+	// Off period is not counted
+	assert.NoError(t, relay.Off())
+	clock.Add(1 * time.Hour)
+	counters = relay.Counters()
+	assert.Equal(t, 30*time.Minute, counters.OnTime)
+	assert.Equal(t, int64(2), counters.Switches)
+
+	// Counters are persisted on change
+	var stored Counters
+	found, err := store.Load("pump", &stored)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, 30*time.Minute, stored.OnTime)
+	assert.Equal(t, int64(2), stored.Switches)
+
+	// Save the current on period
+	assert.NoError(t, relay.On())
+	clock.Add(15 * time.Minute)
+	assert.NoError(t, relay.SaveCounters())
+	_, err = store.Load("pump", &stored)
+	assert.NoError(t, err)
+	assert.Equal(t, 45*time.Minute, stored.OnTime)
+	assert.Equal(t, 45*time.Minute, relay.Counters().OnTime)
+
+	// Counters are loaded on restart
+	relay = newRelay()
+	relay.SetClock(clock)
+	assert.NoError(t, relay.SetAccounting(store, "pump", 1000))
+	counters = relay.Counters()
+	assert.Equal(t, 45*time.Minute, counters.OnTime)
+	assert.Equal(t, int64(3), counters.Switches)
+	assert.InDelta(t, 750, counters.Energy, 0.001)
+
+	// Reset counters
+	assert.NoError(t, relay.ResetCounters())
+	assert.Equal(t, Counters{}, relay.Counters())
+	_, err = store.Load("pump", &stored)
+	assert.NoError(t, err)
+	assert.Equal(t, Counters{}, stored)
+}
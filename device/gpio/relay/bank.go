@@ -0,0 +1,255 @@
+package relay
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/disaster37/go-arest"
+	"github.com/pkg/errors"
+)
+
+// Bank is multi-channel relay board, where all channels share the same signal and output
+type Bank interface {
+
+	// On enable the channel output
+	On(channel int) (err error)
+
+	// Off disable the channel output
+	Off(channel int) (err error)
+
+	// AllOn enable all channels outputs
+	AllOn() (err error)
+
+	// AllOff disable all channels outputs
+	AllOff() (err error)
+
+	// SetMask set all channels outputs from bitmask, where bit N is channel N
+	SetMask(mask uint64) (err error)
+
+	// SetStates set the channels outputs from map of channel to output state
+	SetStates(states map[int]bool) (err error)
+
+	// Mask return the outputs as bitmask, where bit N is channel N
+	Mask() (mask uint64)
+
+	// States return the output state of each channel
+	States() (states map[int]State)
+
+	// Relay return the relay of channel
+	Relay(channel int) (relay Relay, err error)
+
+	// Channels return the channel numbers sorted
+	Channels() (channels []int)
+
+	// Reset permit to reconfigure all channels. It usefull when board reboot
+	Reset() (err error)
+}
+
+// BankImp implement the bank interface
+// It's safe for concurrent use
+type BankImp struct {
+	relays   map[int]Relay
+	channels []int
+	mutex    sync.Mutex
+}
+
+// NewBank return new relay bank from map of channel number to pin.
+// Channel numbers must be between 0 and 63 to be used on bitmask
+// Channels are checked before any pin is driven, then they are init in channel order
+func NewBank(c arest.Arest, pins map[int]int, signal arest.Level, output Output, defaultState State) (bank Bank, err error) {
+	channels := make([]int, 0, len(pins))
+	for channel := range pins {
+		if channel < 0 || channel > 63 {
+			return nil, errors.Errorf("Channel must be between 0 and 63: %d", channel)
+		}
+		channels = append(channels, channel)
+	}
+	sort.Ints(channels)
+
+	relays := make(map[int]Relay, len(pins))
+	for _, channel := range channels {
+		relay, err := NewRelay(c, pins[channel], signal, output, copyState(defaultState))
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error when init channel %d", channel)
+		}
+		relays[channel] = relay
+	}
+
+	return &BankImp{
+		relays:   relays,
+		channels: channels,
+	}, nil
+}
+
+// On enable the channel output
+func (h *BankImp) On(channel int) (err error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return h.set(channel, true)
+}
+
+// Off disable the channel output
+func (h *BankImp) Off(channel int) (err error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return h.set(channel, false)
+}
+
+// AllOn enable all channels outputs
+func (h *BankImp) AllOn() (err error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	for _, channel := range h.channels {
+		if err = h.set(channel, true); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// AllOff disable all channels outputs
+func (h *BankImp) AllOff() (err error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	for _, channel := range h.channels {
+		if err = h.set(channel, false); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetMask set all channels outputs from bitmask, where bit N is channel N.
+// It return error if bitmask enable unknown channel
+func (h *BankImp) SetMask(mask uint64) (err error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	var known uint64
+	for _, channel := range h.channels {
+		known |= 1 << uint(channel)
+	}
+	if mask&^known != 0 {
+		return errors.Errorf("Bitmask %b enable unknown channels", mask&^known)
+	}
+
+	for _, channel := range h.channels {
+		if err = h.set(channel, mask&(1<<uint(channel)) != 0); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetStates set the channels outputs from map of channel to output state.
+// Channels not in map are not changed
+func (h *BankImp) SetStates(states map[int]bool) (err error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	for channel := range states {
+		if _, err = h.relay(channel); err != nil {
+			return err
+		}
+	}
+
+	for _, channel := range h.channels {
+		if isOn, ok := states[channel]; ok {
+			if err = h.set(channel, isOn); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Mask return the outputs as bitmask, where bit N is channel N
+func (h *BankImp) Mask() (mask uint64) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	for _, channel := range h.channels {
+		if h.relays[channel].OutputState().IsOn() {
+			mask |= 1 << uint(channel)
+		}
+	}
+
+	return mask
+}
+
+// States return the output state of each channel
+func (h *BankImp) States() (states map[int]State) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	states = make(map[int]State, len(h.channels))
+	for _, channel := range h.channels {
+		states[channel] = h.relays[channel].OutputState()
+	}
+
+	return states
+}
+
+// Relay return the relay of channel
+func (h *BankImp) Relay(channel int) (relay Relay, err error) {
+	return h.relay(channel)
+}
+
+// Channels return the channel numbers sorted
+func (h *BankImp) Channels() (channels []int) {
+	channels = make([]int, len(h.channels))
+	copy(channels, h.channels)
+
+	return channels
+}
+
+// Reset permit to reconfigure all channels. It usefull when board reboot
+func (h *BankImp) Reset() (err error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	for _, channel := range h.channels {
+		if err = h.relays[channel].Reset(); err != nil {
+			return errors.Wrapf(err, "Error when reset channel %d", channel)
+		}
+	}
+
+	return nil
+}
+
+// set the channel output. Lock must be held
+func (h *BankImp) set(channel int, isOn bool) (err error) {
+	relay, err := h.relay(channel)
+	if err != nil {
+		return err
+	}
+
+	if isOn {
+		err = relay.On()
+	} else {
+		err = relay.Off()
+	}
+	if err != nil {
+		return errors.Wrapf(err, "Error when switch channel %d", channel)
+	}
+
+	return nil
+}
+
+// relay return the relay of channel
+func (h *BankImp) relay(channel int) (Relay, error) {
+	relay, ok := h.relays[channel]
+	if !ok {
+		return nil, errors.Errorf("Channel %d not found in relay bank", channel)
+	}
+
+	return relay, nil
+}
@@ -0,0 +1,91 @@
+package relay
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/disaster37/go-arest"
+	"github.com/disaster37/go-arest/rest"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBank(t *testing.T) {
+	client := rest.MockRestClient()
+	responder := httpmock.NewStringResponder(200, `{}`)
+	pins := map[int]int{1: 10, 2: 11, 3: 12, 4: 13}
+	for _, pin := range pins {
+		for _, url := range []string{"/mode/%d/o", "/digital/%d/1", "/digital/%d/0"} {
+			httpmock.RegisterResponder("POST", "http://localhost"+fmt.Sprintf(url, pin), responder)
+		}
+	}
+
+	signal := arest.NewLevel()
+	signal.SetLevelHigh()
+	output := NewOutput()
+	output.SetOutputNO()
+	defaultState := NewState()
+	defaultState.SetStateOff()
+
+	// Bad channel
+	httpmock.ZeroCallCounters()
+	_, err := NewBank(client, map[int]int{1: 10, 64: 11}, signal, output, defaultState)
+	assert.Error(t, err)
+	assert.Equal(t, 0, httpmock.GetTotalCallCount())
+
+	// Channels are init in order, and stop on the first failure
+	httpmock.RegisterResponder("POST", "http://localhost/mode/32/o", httpmock.NewErrorResponder(http.ErrHandlerTimeout))
+	httpmock.ZeroCallCounters()
+	_, err = NewBank(client, map[int]int{1: 10, 2: 32, 3: 12, 4: 13}, signal, output, defaultState)
+	assert.Error(t, err)
+	info := httpmock.GetCallCountInfo()
+	assert.Equal(t, 1, info["POST http://localhost/mode/10/o"])
+	assert.Equal(t, 0, info["POST http://localhost/mode/12/o"])
+	assert.Equal(t, 0, info["POST http://localhost/mode/13/o"])
+
+	bank, err := NewBank(client, pins, signal, output, defaultState)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3, 4}, bank.Channels())
+	assert.Equal(t, uint64(0), bank.Mask())
+
+	// Single channel
+	assert.NoError(t, bank.On(2))
+	assert.Equal(t, uint64(0x4), bank.Mask())
+	assert.Error(t, bank.On(5))
+	relay, err := bank.Relay(2)
+	assert.NoError(t, err)
+	assert.Equal(t, true, relay.OutputState().IsOn())
+	assert.NoError(t, bank.Off(2))
+	assert.Equal(t, uint64(0), bank.Mask())
+
+	// All channels
+	assert.NoError(t, bank.AllOn())
+	assert.Equal(t, uint64(0x1e), bank.Mask())
+	assert.NoError(t, bank.AllOff())
+	assert.Equal(t, uint64(0), bank.Mask())
+
+	// Bitmask
+	assert.NoError(t, bank.SetMask(0xa))
+	states := bank.States()
+	assert.Equal(t, true, states[1].IsOn())
+	assert.Equal(t, true, states[2].IsOff())
+	assert.Equal(t, true, states[3].IsOn())
+	assert.Equal(t, true, states[4].IsOff())
+	assert.Error(t, bank.SetMask(0x1))
+	assert.Equal(t, uint64(0xa), bank.Mask())
+
+	// Map
+	assert.NoError(t, bank.SetStates(map[int]bool{1: false, 4: true}))
+	assert.Equal(t, uint64(0x18), bank.Mask())
+	assert.Error(t, bank.SetStates(map[int]bool{1: true, 5: true}))
+	assert.Equal(t, uint64(0x18), bank.Mask())
+
+	// Reset
+	httpmock.ZeroCallCounters()
+	assert.NoError(t, bank.Reset())
+	info = httpmock.GetCallCountInfo()
+	assert.Equal(t, 1, info["POST http://localhost/mode/10/o"])
+	assert.Equal(t, 1, info["POST http://localhost/digital/13/1"])
+	assert.Equal(t, uint64(0x18), bank.Mask())
+}
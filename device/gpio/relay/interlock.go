@@ -0,0 +1,243 @@
+package relay
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrInterlockViolation is returned when other member of the interlock group is on
+	ErrInterlockViolation = errors.New("Other relay of interlock group is on")
+
+	// ErrDeadTime is returned when the dead time since the last switch off is not elapsed
+	ErrDeadTime = errors.New("Dead time of interlock group is not elapsed")
+)
+
+// Interlock is group of relays where at most one relay is on at a time.
+// Relays must be switched only through the group
+type Interlock interface {
+
+	// On enable the relay output.
+	// It return ErrInterlockViolation if other relay is on or if its last read back failed,
+	// and ErrDeadTime if dead time is not elapsed
+	On(name string) (err error)
+
+	// Off disable the relay output
+	Off(name string) (err error)
+
+	// AllOff disable all relays outputs
+	AllOff() (err error)
+
+	// Switch disable other relays, wait the dead time and enable the relay output
+	Switch(ctx context.Context, name string) (err error)
+
+	// Active return the name of relay that is on, or empty string if all relays are off
+	Active() (name string)
+}
+
+// InterlockImp implement the interlock interface
+type InterlockImp struct {
+	relays    map[string]Relay
+	names     []string
+	deadTime  time.Duration
+	lastOff   time.Time
+	uncertain map[string]bool
+	mutex     sync.Mutex
+}
+
+// NewInterlock return new interlock group.
+// Dead time is the minimum time between switch off one relay and switch on other relay
+func NewInterlock(deadTime time.Duration, relays map[string]Relay) (interlock Interlock, err error) {
+	names := make([]string, 0, len(relays))
+	nbOn := 0
+	for name, relay := range relays {
+		names = append(names, name)
+		if relay.OutputState().IsOn() {
+			nbOn++
+		}
+	}
+	sort.Strings(names)
+
+	if nbOn > 1 {
+		return nil, errors.Wrapf(ErrInterlockViolation, "%d relays are on", nbOn)
+	}
+
+	return &InterlockImp{
+		relays:    relays,
+		names:     names,
+		deadTime:  deadTime,
+		uncertain: make(map[string]bool),
+	}, nil
+}
+
+// On enable the relay output.
+// It return ErrInterlockViolation if other relay is on or if its last read back failed,
+// and ErrDeadTime if dead time is not elapsed
+func (h *InterlockImp) On(name string) (err error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return h.on(name)
+}
+
+// Off disable the relay output
+func (h *InterlockImp) Off(name string) (err error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	relay, err := h.relay(name)
+	if err != nil {
+		return err
+	}
+
+	return h.off(name, relay)
+}
+
+// AllOff disable all relays outputs
+func (h *InterlockImp) AllOff() (err error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return h.allOff("")
+}
+
+// Switch disable other relays, wait the dead time and enable the relay output.
+// The group is not locked during the wait, so AllOff can still stop it
+func (h *InterlockImp) Switch(ctx context.Context, name string) (err error) {
+	h.mutex.Lock()
+	relay, err := h.relay(name)
+	if err != nil {
+		h.mutex.Unlock()
+		return err
+	}
+	if err = h.allOff(name); err != nil {
+		h.mutex.Unlock()
+		return err
+	}
+	wait := h.remainingDeadTime()
+	h.mutex.Unlock()
+
+	if wait > 0 && relay.OutputState().IsOff() {
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	return h.On(name)
+}
+
+// Active return the name of relay that is on, or empty string if all relays are off
+func (h *InterlockImp) Active() (name string) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return h.active()
+}
+
+// on enable the relay output if no other relay is on. Lock must be held
+func (h *InterlockImp) on(name string) (err error) {
+	relay, err := h.relay(name)
+	if err != nil {
+		return err
+	}
+
+	active := h.active()
+	if active == name {
+		return nil
+	}
+	if active != "" {
+		return errors.Wrapf(ErrInterlockViolation, "Relay %s is on", active)
+	}
+	for _, other := range h.names {
+		if other != name && h.uncertain[other] {
+			return errors.Wrapf(ErrInterlockViolation, "Read back of relay %s failed, it can be on", other)
+		}
+	}
+	if wait := h.remainingDeadTime(); wait > 0 {
+		return errors.Wrapf(ErrDeadTime, "Wait %s before switch on relay %s", wait, name)
+	}
+
+	err = relay.On()
+	h.track(name, err)
+
+	return err
+}
+
+// off disable the relay output. Lock must be held
+// When its read back failed, the relay is written again because it can still be on
+func (h *InterlockImp) off(name string, relay Relay) (err error) {
+	if relay.OutputState().IsOff() && !h.uncertain[name] {
+		return nil
+	}
+
+	err = relay.Off()
+	// Relay is switched even if its read back failed
+	if err != nil && errors.Cause(err) != ErrReadbackMismatch {
+		return err
+	}
+	h.lastOff = time.Now()
+	h.track(name, err)
+
+	return err
+}
+
+// track remember the relays which state is uncertain because their read back failed. Lock must be held
+func (h *InterlockImp) track(name string, err error) {
+	if errors.Cause(err) == ErrReadbackMismatch {
+		h.uncertain[name] = true
+	} else if err == nil {
+		delete(h.uncertain, name)
+	}
+}
+
+// allOff disable all relays outputs except the relay name. Lock must be held
+func (h *InterlockImp) allOff(except string) (err error) {
+	for _, name := range h.names {
+		if name == except {
+			continue
+		}
+		if err = h.off(name, h.relays[name]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// active return the name of relay that is on. Lock must be held
+func (h *InterlockImp) active() string {
+	for _, name := range h.names {
+		if h.relays[name].OutputState().IsOn() {
+			return name
+		}
+	}
+
+	return ""
+}
+
+// remainingDeadTime return the time to wait before switch on relay. Lock must be held
+func (h *InterlockImp) remainingDeadTime() time.Duration {
+	if h.lastOff.IsZero() {
+		return 0
+	}
+
+	return h.deadTime - time.Since(h.lastOff)
+}
+
+// relay return the relay from its name
+func (h *InterlockImp) relay(name string) (Relay, error) {
+	relay, ok := h.relays[name]
+	if !ok {
+		return nil, errors.Errorf("Relay %s not found in interlock group", name)
+	}
+
+	return relay, nil
+}
@@ -0,0 +1,137 @@
+package relay
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/disaster37/go-arest"
+	"github.com/disaster37/go-arest/rest"
+	"github.com/jarcoal/httpmock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterlock(t *testing.T) {
+	client := rest.MockRestClient()
+	responder := httpmock.NewStringResponder(200, `{}`)
+	for _, url := range []string{"/mode/3/o", "/digital/3/1", "/digital/3/0", "/mode/4/o", "/digital/4/1", "/digital/4/0"} {
+		httpmock.RegisterResponder("POST", "http://localhost"+url, responder)
+	}
+
+	newRelay := func(pin int, isOn bool) Relay {
+		signal := arest.NewLevel()
+		signal.SetLevelHigh()
+		output := NewOutput()
+		output.SetOutputNO()
+		defaultState := NewState()
+		if isOn {
+			defaultState.SetStateOn()
+		} else {
+			defaultState.SetStateOff()
+		}
+		relay, err := NewRelay(client, pin, signal, output, defaultState)
+		assert.NoError(t, err)
+		return relay
+	}
+
+	// More than one relay on
+	_, err := NewInterlock(100*time.Millisecond, map[string]Relay{"up": newRelay(3, true), "down": newRelay(4, true)})
+	assert.Equal(t, ErrInterlockViolation, errors.Cause(err))
+
+	up := newRelay(3, false)
+	down := newRelay(4, false)
+	interlock, err := NewInterlock(100*time.Millisecond, map[string]Relay{"up": up, "down": down})
+	assert.NoError(t, err)
+	assert.Equal(t, "", interlock.Active())
+
+	// On
+	assert.NoError(t, interlock.On("up"))
+	assert.Equal(t, "up", interlock.Active())
+	assert.NoError(t, interlock.On("up"))
+	assert.Error(t, interlock.On("bad"))
+
+	// Other relay is on
+	err = interlock.On("down")
+	assert.Equal(t, ErrInterlockViolation, errors.Cause(err))
+	assert.Equal(t, true, down.OutputState().IsOff())
+
+	// Dead time
+	assert.NoError(t, interlock.Off("up"))
+	err = interlock.On("down")
+	assert.Equal(t, ErrDeadTime, errors.Cause(err))
+	assert.Equal(t, "", interlock.Active())
+	time.Sleep(100 * time.Millisecond)
+	assert.NoError(t, interlock.On("down"))
+	assert.Equal(t, "down", interlock.Active())
+
+	// Switch wait the dead time
+	start := time.Now()
+	assert.NoError(t, interlock.Switch(context.Background(), "up"))
+	assert.True(t, time.Since(start) >= 100*time.Millisecond)
+	assert.Equal(t, "up", interlock.Active())
+	assert.Equal(t, true, down.OutputState().IsOff())
+
+	// Switch canceled
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err = interlock.Switch(ctx, "down")
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Equal(t, "", interlock.Active())
+
+	// All off
+	time.Sleep(100 * time.Millisecond)
+	assert.NoError(t, interlock.On("down"))
+	assert.NoError(t, interlock.AllOff())
+	assert.Equal(t, "", interlock.Active())
+}
+
+func TestInterlockReadback(t *testing.T) {
+	client := rest.MockRestClient()
+	responder := httpmock.NewStringResponder(200, `{}`)
+	for _, url := range []string{"/mode/33/o", "/digital/33/1", "/digital/33/0", "/mode/34/o", "/digital/34/1", "/digital/34/0"} {
+		httpmock.RegisterResponder("POST", "http://localhost"+url, responder)
+	}
+	readLevel := func(pin int, level int) {
+		httpmock.RegisterResponder("GET", fmt.Sprintf("http://localhost/digital/%d", pin), httpmock.NewJsonResponderOrPanic(200, map[string]interface{}{
+			"return_value": level,
+		}))
+	}
+
+	signal := arest.NewLevel()
+	signal.SetLevelHigh()
+	output := NewOutput()
+	output.SetOutputNO()
+	onState := NewState()
+	onState.SetStateOn()
+	offState := NewState()
+	offState.SetStateOff()
+	up, err := NewRelay(client, 33, signal, output, onState)
+	assert.NoError(t, err)
+	up.(*RelayImp).SetReadback(true, false)
+	down, err := NewRelay(client, 34, signal, output, offState)
+	assert.NoError(t, err)
+	down.(*RelayImp).SetReadback(true, false)
+	interlock, err := NewInterlock(1*time.Hour, map[string]Relay{"up": up, "down": down})
+	assert.NoError(t, err)
+
+	// Pin of up is stuck high, so down is refused
+	readLevel(33, 1)
+	err = interlock.Off("up")
+	assert.Equal(t, ErrReadbackMismatch, errors.Cause(err))
+	err = interlock.On("down")
+	assert.Equal(t, ErrInterlockViolation, errors.Cause(err))
+	assert.Equal(t, true, down.OutputState().IsOff())
+	err = interlock.Switch(context.Background(), "down")
+	assert.Equal(t, ErrReadbackMismatch, errors.Cause(err))
+	assert.Equal(t, true, down.OutputState().IsOff())
+
+	// Up is really off, dead time start from the last switch off
+	readLevel(33, 0)
+	httpmock.ZeroCallCounters()
+	assert.NoError(t, interlock.Off("up"))
+	assert.Equal(t, 1, httpmock.GetCallCountInfo()["POST http://localhost/digital/33/0"])
+	err = interlock.On("down")
+	assert.Equal(t, ErrDeadTime, errors.Cause(err))
+}
@@ -0,0 +1,297 @@
+package relay
+
+import (
+	"sync"
+	"time"
+
+	"github.com/disaster37/go-arest"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// ErrLatchFailed is returned when feedback pin not confirm the latched state
+var ErrLatchFailed = errors.New("Latching relay feedback not match the latched state")
+
+const (
+	// releaseRetries is the number of attempts to release coil
+	releaseRetries = 3
+
+	// releaseRetryDelay is the time between two attempts to release coil
+	releaseRetryDelay = 10 * time.Millisecond
+)
+
+// LatchingRelayImp implement the relay interface for bistable relay with SET and RESET coils.
+// State and OutputState are the same, because there is no NO / NC output
+// It's safe for concurrent use
+type LatchingRelayImp struct {
+	client         arest.Arest
+	setPin         int
+	resetPin       int
+	signal         arest.Level
+	pulseWidth     time.Duration
+	feedbackPin    int
+	feedbackSignal arest.Level
+	outputState    State
+	timer          *time.Timer
+	timerID        uint64
+	mutex          sync.Mutex
+}
+
+// NewLatchingRelay return new latching relay object.
+// Signal is the level that energize the coils, and pulse width is the time the coil stay energized
+func NewLatchingRelay(c arest.Arest, setPin int, resetPin int, signal arest.Level, pulseWidth time.Duration, defaultState State) (relay Relay, err error) {
+	if setPin == resetPin {
+		return nil, errors.Errorf("SET and RESET pins must be different: %d", setPin)
+	}
+	if pulseWidth <= 0 {
+		return nil, errors.Errorf("Pulse width must be positive: %s", pulseWidth)
+	}
+
+	relay = &LatchingRelayImp{
+		client:      c,
+		setPin:      setPin,
+		resetPin:    resetPin,
+		signal:      signal,
+		pulseWidth:  pulseWidth,
+		feedbackPin: -1,
+		outputState: copyState(defaultState),
+	}
+
+	err = relay.Reset()
+
+	return relay, err
+}
+
+// SetFeedback permit to confirm the latched state with input pin.
+// Signal is the pin level when relay is latched on
+func (r *LatchingRelayImp) SetFeedback(pin int, signal arest.Level) (err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	mode := arest.NewMode()
+	mode.SetModeInput()
+	if err = r.client.SetPinMode(pin, mode); err != nil {
+		return err
+	}
+
+	r.feedbackPin = pin
+	r.feedbackSignal = signal
+
+	return nil
+}
+
+// On pulse the SET coil
+// It cancel the pending pulse or OnFor timer
+func (r *LatchingRelayImp) On() (err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.cancelTimer()
+	return r.latch(true)
+}
+
+// Off pulse the RESET coil
+// It cancel the pending pulse or OnFor timer
+func (r *LatchingRelayImp) Off() (err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.cancelTimer()
+	return r.latch(false)
+}
+
+// Toggle invert the latched state
+// It cancel the pending pulse or OnFor timer
+func (r *LatchingRelayImp) Toggle() (err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.cancelTimer()
+	return r.latch(!r.outputState.IsOn())
+}
+
+// Pulse invert the latched state during duration, then restore it
+// It replace the pending pulse or OnFor timer
+func (r *LatchingRelayImp) Pulse(duration time.Duration) (err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.cancelTimer()
+	restoreOn := r.outputState.IsOn()
+	err = r.latch(!restoreOn)
+
+	// Relay can be latched even if latch failed, so it must be restored
+	if r.outputState.IsOn() != restoreOn {
+		r.schedule(duration, restoreOn)
+	}
+	return err
+}
+
+// OnFor latch on during duration, then latch off
+// It replace the pending pulse or OnFor timer
+func (r *LatchingRelayImp) OnFor(duration time.Duration) (err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.cancelTimer()
+	err = r.latch(true)
+
+	// Relay can be latched even if latch failed, so it must be latched off
+	if r.outputState.IsOn() {
+		r.schedule(duration, false)
+	}
+	return err
+}
+
+// Cancel stop the pending pulse or OnFor timer and keep the current state
+func (r *LatchingRelayImp) Cancel() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.cancelTimer()
+}
+
+// State return the latched state
+func (r *LatchingRelayImp) State() State {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return copyState(r.outputState)
+}
+
+// OutputState return the latched state
+func (r *LatchingRelayImp) OutputState() State {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return copyState(r.outputState)
+}
+
+// Reset permit to reconfigure relay. It usefull when board reboot
+// It release both coils and latch the desired state again
+func (r *LatchingRelayImp) Reset() (err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	mode := arest.NewMode()
+	mode.SetModeOutput()
+	for _, pin := range []int{r.setPin, r.resetPin} {
+		if err = r.client.SetPinMode(pin, mode); err != nil {
+			return err
+		}
+		if err = r.client.DigitalWrite(pin, r.inactiveLevel()); err != nil {
+			return err
+		}
+	}
+
+	if r.feedbackPin >= 0 {
+		mode = arest.NewMode()
+		mode.SetModeInput()
+		if err = r.client.SetPinMode(r.feedbackPin, mode); err != nil {
+			return err
+		}
+	}
+
+	return r.latch(r.outputState.IsOn())
+}
+
+// latch pulse the coil and confirm the state with feedback pin. Lock must be held
+func (r *LatchingRelayImp) latch(isOn bool) (err error) {
+	pin := r.resetPin
+	if isOn {
+		pin = r.setPin
+	}
+
+	if err = r.client.DigitalWrite(pin, r.signal); err != nil {
+		// The coil can be energized even if the response is lost
+		if releaseErr := r.release(pin); releaseErr != nil {
+			log.Errorf("Error appear when release coil on pin %d: %s", pin, releaseErr.Error())
+		}
+		return err
+	}
+	time.Sleep(r.pulseWidth)
+	releaseErr := r.release(pin)
+
+	// The relay is latched even if the coil is not released
+	if isOn {
+		r.outputState.SetStateOn()
+	} else {
+		r.outputState.SetStateOff()
+	}
+	if releaseErr != nil {
+		return errors.Wrapf(releaseErr, "Error when release coil on pin %d", pin)
+	}
+
+	if r.feedbackPin < 0 {
+		return nil
+	}
+
+	level, err := r.client.DigitalRead(r.feedbackPin)
+	if err != nil {
+		return err
+	}
+	if (level.IsHigh() == r.feedbackSignal.IsHigh()) != isOn {
+		// Keep the state really latched
+		if isOn {
+			r.outputState.SetStateOff()
+		} else {
+			r.outputState.SetStateOn()
+		}
+		return errors.Wrapf(ErrLatchFailed, "Feedback pin %d read %s", r.feedbackPin, level.String())
+	}
+
+	return nil
+}
+
+// release de-energize the coil. It retry because coil can burn out if it stay energized
+func (r *LatchingRelayImp) release(pin int) (err error) {
+	for i := 0; i < releaseRetries; i++ {
+		if err = r.client.DigitalWrite(pin, r.inactiveLevel()); err == nil {
+			return nil
+		}
+		log.Warnf("Error appear when release coil on pin %d, retry: %s", pin, err.Error())
+		time.Sleep(releaseRetryDelay)
+	}
+
+	return err
+}
+
+// inactiveLevel return the level that not energize the coils
+func (r *LatchingRelayImp) inactiveLevel() arest.Level {
+	level := arest.NewLevel()
+	if r.signal.IsHigh() {
+		level.SetLevelLow()
+	} else {
+		level.SetLevelHigh()
+	}
+
+	return level
+}
+
+// schedule latch the state after duration. Lock must be held
+func (r *LatchingRelayImp) schedule(duration time.Duration, isOn bool) {
+	id := r.timerID
+	r.timer = time.AfterFunc(duration, func() {
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+
+		// Timer is canceled or replaced while waiting the lock
+		if id != r.timerID {
+			return
+		}
+		r.timer = nil
+
+		if err := r.latch(isOn); err != nil {
+			log.Errorf("Error appear when latch relay at the end of timer: %s", err.Error())
+		}
+	})
+}
+
+// cancelTimer stop the pending timer. Lock must be held
+func (r *LatchingRelayImp) cancelTimer() {
+	if r.timer != nil {
+		r.timer.Stop()
+		r.timer = nil
+	}
+	r.timerID++
+}
@@ -0,0 +1,141 @@
+package relay
+
+import (
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/disaster37/go-arest"
+	"github.com/disaster37/go-arest/rest"
+	"github.com/jarcoal/httpmock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLatchingRelay(t *testing.T) {
+	client := rest.MockRestClient()
+	responder := httpmock.NewStringResponder(200, `{}`)
+	for _, url := range []string{"/mode/20/o", "/digital/20/1", "/digital/20/0", "/mode/21/o", "/digital/21/1", "/digital/21/0", "/mode/22/i"} {
+		httpmock.RegisterResponder("POST", "http://localhost"+url, responder)
+	}
+	readFeedback := func(level int) {
+		httpmock.RegisterResponder("GET", "http://localhost/digital/22", httpmock.NewJsonResponderOrPanic(200, map[string]interface{}{
+			"return_value": level,
+		}))
+	}
+
+	signal := arest.NewLevel()
+	signal.SetLevelHigh()
+	defaultState := NewState()
+	defaultState.SetStateOff()
+
+	// Same pin for both coils
+	_, err := NewLatchingRelay(client, 20, 20, signal, 10*time.Millisecond, defaultState)
+	assert.Error(t, err)
+
+	// Bad pulse width
+	_, err = NewLatchingRelay(client, 20, 21, signal, 0, defaultState)
+	assert.Error(t, err)
+
+	// Reset latch the default state
+	httpmock.ZeroCallCounters()
+	relay, err := NewLatchingRelay(client, 20, 21, signal, 10*time.Millisecond, defaultState)
+	assert.NoError(t, err)
+	assert.Equal(t, true, relay.OutputState().IsOff())
+	assert.Equal(t, true, relay.State().IsOff())
+	info := httpmock.GetCallCountInfo()
+	assert.Equal(t, 0, info["POST http://localhost/digital/20/1"])
+	assert.Equal(t, 1, info["POST http://localhost/digital/21/1"])
+	assert.Equal(t, 2, info["POST http://localhost/digital/21/0"])
+
+	// On pulse the SET coil during the pulse width
+	httpmock.ZeroCallCounters()
+	start := time.Now()
+	assert.NoError(t, relay.On())
+	assert.True(t, time.Since(start) >= 10*time.Millisecond)
+	assert.Equal(t, true, relay.OutputState().IsOn())
+	info = httpmock.GetCallCountInfo()
+	assert.Equal(t, 1, info["POST http://localhost/digital/20/1"])
+	assert.Equal(t, 1, info["POST http://localhost/digital/20/0"])
+	assert.Equal(t, 0, info["POST http://localhost/digital/21/1"])
+
+	// Off pulse the RESET coil
+	httpmock.ZeroCallCounters()
+	assert.NoError(t, relay.Toggle())
+	assert.Equal(t, true, relay.OutputState().IsOff())
+	info = httpmock.GetCallCountInfo()
+	assert.Equal(t, 1, info["POST http://localhost/digital/21/1"])
+	assert.Equal(t, 1, info["POST http://localhost/digital/21/0"])
+
+	// OnFor
+	assert.NoError(t, relay.OnFor(50*time.Millisecond))
+	assert.Equal(t, true, relay.OutputState().IsOn())
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, true, relay.OutputState().IsOff())
+
+	// Feedback confirm the state
+	latching := relay.(*LatchingRelayImp)
+	assert.NoError(t, latching.SetFeedback(22, signal))
+	readFeedback(1)
+	assert.NoError(t, relay.On())
+	assert.Equal(t, true, relay.OutputState().IsOn())
+
+	// Feedback not confirm the state
+	err = relay.Off()
+	assert.Equal(t, ErrLatchFailed, errors.Cause(err))
+	assert.Equal(t, true, relay.OutputState().IsOn())
+}
+
+func TestLatchingRelayRelease(t *testing.T) {
+	client := rest.MockRestClient()
+	responder := httpmock.NewStringResponder(200, `{}`)
+	for _, url := range []string{"/mode/23/o", "/digital/23/1", "/digital/23/0", "/mode/24/o", "/digital/24/1", "/digital/24/0"} {
+		httpmock.RegisterResponder("POST", "http://localhost"+url, responder)
+	}
+
+	signal := arest.NewLevel()
+	signal.SetLevelHigh()
+	defaultState := NewState()
+	defaultState.SetStateOff()
+	relay, err := NewLatchingRelay(client, 23, 24, signal, 10*time.Millisecond, defaultState)
+	assert.NoError(t, err)
+
+	// Release is retried
+	var nbRelease, nbFailure int32
+	httpmock.RegisterResponder("POST", "http://localhost/digital/23/0", func(req *http.Request) (*http.Response, error) {
+		atomic.AddInt32(&nbRelease, 1)
+		if atomic.AddInt32(&nbFailure, -1) >= 0 {
+			return nil, http.ErrHandlerTimeout
+		}
+		return httpmock.NewStringResponse(200, `{}`), nil
+	})
+	atomic.StoreInt32(&nbFailure, 2)
+	assert.NoError(t, relay.On())
+	assert.Equal(t, int32(3), atomic.LoadInt32(&nbRelease))
+	assert.Equal(t, true, relay.OutputState().IsOn())
+
+	// Latched state is kept when release failed
+	assert.NoError(t, relay.Off())
+	atomic.StoreInt32(&nbRelease, 0)
+	atomic.StoreInt32(&nbFailure, 10)
+	assert.Error(t, relay.On())
+	assert.Equal(t, int32(releaseRetries), atomic.LoadInt32(&nbRelease))
+	assert.Equal(t, true, relay.OutputState().IsOn())
+
+	// OnFor latch off even if release failed
+	assert.NoError(t, relay.Off())
+	atomic.StoreInt32(&nbFailure, 10)
+	assert.Error(t, relay.OnFor(20*time.Millisecond))
+	atomic.StoreInt32(&nbFailure, 0)
+	assert.Equal(t, true, relay.OutputState().IsOn())
+	time.Sleep(80 * time.Millisecond)
+	assert.Equal(t, true, relay.OutputState().IsOff())
+
+	// Release is attempted when energize failed
+	httpmock.RegisterResponder("POST", "http://localhost/digital/23/1", httpmock.NewErrorResponder(http.ErrHandlerTimeout))
+	atomic.StoreInt32(&nbRelease, 0)
+	atomic.StoreInt32(&nbFailure, 0)
+	assert.Error(t, relay.On())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&nbRelease))
+}
@@ -0,0 +1,80 @@
+package relay
+
+import (
+	"github.com/disaster37/go-arest"
+	"github.com/disaster37/go-arest/device"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// Restore policies, to choose the output state on startup
+const (
+	// RestoreLast apply the last persisted output state, or the default state if there is none
+	RestoreLast string = "restore"
+
+	// RestoreDefault apply the default state
+	RestoreDefault string = "default"
+
+	// RestoreOff disable the output
+	RestoreOff string = "off"
+)
+
+// NewPersistentRelay return new relay object that persist its output state on store under key.
+// The output state applied on startup depend of the restore policy.
+// With RestoreLast, the default state is used when persisted state is unreadable.
+// While pulse or OnFor timer is pending, the output at the end of timer is persisted instead of the temporary one
+func NewPersistentRelay(c arest.Arest, pin int, signal arest.Level, output Output, defaultState State, store device.Store, key string, policy string) (relay Relay, err error) {
+	initialState := copyState(defaultState)
+
+	switch policy {
+	case RestoreLast:
+		// Unreadable state must not prevent the relay to start, so the default state is used
+		var state string
+		found, err := store.Load(key, &state)
+		if err != nil {
+			log.Warnf("Error appear when load state of relay %s, use default state: %s", key, err.Error())
+		} else if found {
+			switch state {
+			case on:
+				initialState.SetStateOn()
+			case off:
+				initialState.SetStateOff()
+			default:
+				log.Warnf("State %s of relay %s not supported, use default state", state, key)
+			}
+		}
+	case RestoreDefault:
+	case RestoreOff:
+		initialState.SetStateOff()
+	default:
+		return nil, errors.Errorf("Restore policy %s not supported", policy)
+	}
+
+	r := newRelay(c, pin, signal, output, initialState)
+	r.store = store
+	r.storeKey = key
+	err = r.Reset()
+
+	return r, err
+}
+
+// save persist the output state. Lock must be held
+// When pulse or OnFor timer is pending, it persist the output at the end of timer.
+// The relay is already switched, so error is only logged
+func (r *RelayImp) save() {
+	if r.store == nil {
+		return
+	}
+
+	state := r.outputState.State()
+	if r.timer != nil {
+		state = off
+		if r.timerOn {
+			state = on
+		}
+	}
+
+	if err := r.store.Save(r.storeKey, state); err != nil {
+		log.Errorf("Error appear when save state of relay %s: %s", r.storeKey, err.Error())
+	}
+}
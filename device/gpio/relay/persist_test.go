@@ -0,0 +1,100 @@
+package relay
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/disaster37/go-arest"
+	"github.com/disaster37/go-arest/device"
+	"github.com/disaster37/go-arest/rest"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPersistentRelay(t *testing.T) {
+	client := rest.MockRestClient()
+	responder := httpmock.NewStringResponder(200, `{}`)
+	for _, url := range []string{"/mode/7/o", "/digital/7/1", "/digital/7/0"} {
+		httpmock.RegisterResponder("POST", "http://localhost"+url, responder)
+	}
+
+	signal := arest.NewLevel()
+	signal.SetLevelHigh()
+	output := NewOutput()
+	output.SetOutputNO()
+	defaultState := NewState()
+	defaultState.SetStateOff()
+	store := device.NewMemoryStore()
+
+	// Bad policy
+	_, err := NewPersistentRelay(client, 7, signal, output, defaultState, store, "heater", "bad")
+	assert.Error(t, err)
+
+	// Nothing persisted, so default state is used
+	relay, err := NewPersistentRelay(client, 7, signal, output, defaultState, store, "heater", RestoreLast)
+	assert.NoError(t, err)
+	assert.Equal(t, true, relay.OutputState().IsOff())
+	assert.True(t, defaultState.IsOff())
+
+	// State is persisted on change
+	assert.NoError(t, relay.On())
+	var state string
+	found, err := store.Load("heater", &state)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "on", state)
+
+	// Temporary output of OnFor and pulse is not persisted
+	load := func() string {
+		var state string
+		_, err := store.Load("heater", &state)
+		assert.NoError(t, err)
+		return state
+	}
+	assert.NoError(t, relay.Off())
+	assert.NoError(t, relay.OnFor(1*time.Hour))
+	assert.Equal(t, "off", load())
+	relay.Cancel()
+	assert.Equal(t, "on", load())
+	assert.NoError(t, relay.Pulse(1*time.Hour))
+	assert.Equal(t, "on", load())
+	assert.NoError(t, relay.Off())
+	assert.Equal(t, "off", load())
+	assert.NoError(t, relay.OnFor(20*time.Millisecond))
+	time.Sleep(80 * time.Millisecond)
+	assert.Equal(t, "off", load())
+	assert.NoError(t, relay.On())
+
+	// Restore last state
+	relay, err = NewPersistentRelay(client, 7, signal, output, defaultState, store, "heater", RestoreLast)
+	assert.NoError(t, err)
+	assert.Equal(t, true, relay.OutputState().IsOn())
+
+	// Default state
+	relay, err = NewPersistentRelay(client, 7, signal, output, defaultState, store, "heater", RestoreDefault)
+	assert.NoError(t, err)
+	assert.Equal(t, true, relay.OutputState().IsOff())
+
+	// Always off
+	assert.NoError(t, relay.On())
+	defaultState.SetStateOn()
+	relay, err = NewPersistentRelay(client, 7, signal, output, defaultState, store, "heater", RestoreOff)
+	assert.NoError(t, err)
+	assert.Equal(t, true, relay.OutputState().IsOff())
+
+	// Corrupted state fallback to default state
+	assert.NoError(t, store.Save("heater", "bad"))
+	relay, err = NewPersistentRelay(client, 7, signal, output, defaultState, store, "heater", RestoreLast)
+	assert.NoError(t, err)
+	assert.Equal(t, true, relay.OutputState().IsOn())
+
+	// Corrupted store fallback to default state
+	path := filepath.Join(t.TempDir(), "relays.json")
+	assert.NoError(t, ioutil.WriteFile(path, []byte{}, 0600))
+	defaultState.SetStateOff()
+	relay, err = NewPersistentRelay(client, 7, signal, output, defaultState, device.NewFileStore(path), "heater", RestoreLast)
+	assert.NoError(t, err)
+	assert.Equal(t, true, relay.OutputState().IsOff())
+}
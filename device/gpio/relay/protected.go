@@ -0,0 +1,311 @@
+package relay
+
+import (
+	"sync"
+	"time"
+
+	"github.com/disaster37/go-arest/device"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// Protection policies, when switch is requested inside protection window
+const (
+	// ProtectionDefer apply the switch when protection window end
+	ProtectionDefer string = "defer"
+
+	// ProtectionReject return ErrProtected
+	ProtectionReject string = "reject"
+)
+
+// ErrProtected is returned when switch is rejected because it's inside protection window
+var ErrProtected = errors.New("Relay is protected against short cycle")
+
+// ProtectedRelay is relay protected against short cycle
+type ProtectedRelay interface {
+	Relay
+
+	// Pending return the deferred output state and when it will be applied.
+	// State is nil when there is no deferred switch
+	Pending() (state State, at time.Time)
+}
+
+// ProtectedRelayImp implement the protected relay interface
+// It's safe for concurrent use
+type ProtectedRelayImp struct {
+	relay        Relay
+	minOn        time.Duration
+	minOff       time.Duration
+	maxSwitches  int
+	policy       string
+	clock        device.Clock
+	lastChange   time.Time
+	starts       []time.Time
+	pending      *time.Timer
+	pendingState State
+	pendingAt    time.Time
+	timer        *time.Timer
+	timerID      uint64
+	mutex        sync.Mutex
+}
+
+// NewProtectedRelay return relay that enforce minimum on time, minimum off time
+// and maximum switch on per hour (0 to disable it).
+// Policy is ProtectionDefer or ProtectionReject
+func NewProtectedRelay(relay Relay, minOn time.Duration, minOff time.Duration, maxSwitches int, policy string) (ProtectedRelay, error) {
+	if policy != ProtectionDefer && policy != ProtectionReject {
+		return nil, errors.Errorf("Protection policy %s not supported", policy)
+	}
+
+	return &ProtectedRelayImp{
+		relay:       relay,
+		minOn:       minOn,
+		minOff:      minOff,
+		maxSwitches: maxSwitches,
+		policy:      policy,
+		clock:       device.NewClock(),
+		starts:      make([]time.Time, 0),
+	}, nil
+}
+
+// SetClock permit to use custom clock
+func (r *ProtectedRelayImp) SetClock(clock device.Clock) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.clock = clock
+}
+
+// On enable the relay output, or defer it
+func (r *ProtectedRelayImp) On() (err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.cancelTimer()
+	return r.set(true, r.policy)
+}
+
+// Off disable the relay output, or defer it
+func (r *ProtectedRelayImp) Off() (err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.cancelTimer()
+	return r.set(false, r.policy)
+}
+
+// Toggle invert the relay output, or defer it
+func (r *ProtectedRelayImp) Toggle() (err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.cancelTimer()
+	return r.set(r.relay.OutputState().IsOff(), r.policy)
+}
+
+// Pulse invert the relay output during duration, then restore it.
+// The duration start when the first switch is applied.
+// The restore is deferred, even with reject policy, until protection permit it
+func (r *ProtectedRelayImp) Pulse(duration time.Duration) (err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.cancelTimer()
+	restoreOn := r.relay.OutputState().IsOn()
+	err = r.set(!restoreOn, r.policy)
+	if err != nil && errors.Cause(err) != ErrReadbackMismatch {
+		return err
+	}
+
+	r.schedule(duration, restoreOn)
+	return err
+}
+
+// OnFor enable the relay output during duration, then disable it.
+// The duration start when the relay is enabled.
+// The switch off is deferred, even with reject policy, until protection permit it
+func (r *ProtectedRelayImp) OnFor(duration time.Duration) (err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.cancelTimer()
+	err = r.set(true, r.policy)
+	if err != nil && errors.Cause(err) != ErrReadbackMismatch {
+		return err
+	}
+
+	r.schedule(duration, false)
+	return err
+}
+
+// Cancel stop the deferred switch and the pending pulse or OnFor timer
+func (r *ProtectedRelayImp) Cancel() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.cancelTimer()
+	r.cancelPending()
+}
+
+// State return the current relay state
+func (r *ProtectedRelayImp) State() State {
+	return r.relay.State()
+}
+
+// OutputState return the current output state
+func (r *ProtectedRelayImp) OutputState() State {
+	return r.relay.OutputState()
+}
+
+// Reset permit to reconfigure relay. It usefull when board reboot
+// It's not counted as switch
+func (r *ProtectedRelayImp) Reset() (err error) {
+	return r.relay.Reset()
+}
+
+// Pending return the deferred output state and when it will be applied.
+// State is nil when there is no deferred switch
+func (r *ProtectedRelayImp) Pending() (state State, at time.Time) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.pendingState == nil {
+		return nil, at
+	}
+
+	return copyState(r.pendingState), r.pendingAt
+}
+
+// set switch the relay if protection permit it, else defer or reject it according to policy. Lock must be held
+func (r *ProtectedRelayImp) set(on bool, policy string) (err error) {
+	r.cancelPending()
+	if r.relay.OutputState().IsOn() == on {
+		return nil
+	}
+
+	now := r.clock.Now()
+	at := r.availableAt(on, now)
+	if !at.After(now) {
+		return r.apply(on, now)
+	}
+
+	if policy == ProtectionReject {
+		return errors.Wrapf(ErrProtected, "Switch is available at %s", at.Format(time.RFC3339))
+	}
+
+	log.Debugf("Switch relay on=%t is deferred at %s", on, at.Format(time.RFC3339))
+
+	r.pendingState = NewState()
+	if on {
+		r.pendingState.SetStateOn()
+	} else {
+		r.pendingState.SetStateOff()
+	}
+	r.pendingAt = at
+	pendingState := r.pendingState
+	r.pending = time.AfterFunc(at.Sub(now), func() {
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+
+		// Pending switch is canceled or replaced while waiting the lock
+		if r.pendingState != pendingState {
+			return
+		}
+		r.pending = nil
+		r.pendingState = nil
+		if err := r.apply(on, r.clock.Now()); err != nil {
+			log.Errorf("Error appear when apply deferred relay switch: %s", err.Error())
+		}
+	})
+
+	return nil
+}
+
+// apply switch the relay and keep the switch time. Lock must be held
+func (r *ProtectedRelayImp) apply(on bool, now time.Time) (err error) {
+	if on {
+		err = r.relay.On()
+	} else {
+		err = r.relay.Off()
+	}
+	// Relay is switched even if its read back failed
+	if err != nil && errors.Cause(err) != ErrReadbackMismatch {
+		return err
+	}
+
+	r.lastChange = now
+	if on {
+		r.starts = append(r.starts, now)
+	}
+
+	return err
+}
+
+// availableAt return when the switch is permitted. Lock must be held
+func (r *ProtectedRelayImp) availableAt(on bool, now time.Time) time.Time {
+	at := now
+	if !r.lastChange.IsZero() {
+		if on {
+			at = r.lastChange.Add(r.minOff)
+		} else {
+			at = r.lastChange.Add(r.minOn)
+		}
+	}
+
+	if on && r.maxSwitches > 0 {
+		// Forget the starts older than one hour
+		for len(r.starts) > 0 && now.Sub(r.starts[0]) >= time.Hour {
+			r.starts = r.starts[1:]
+		}
+		if len(r.starts) >= r.maxSwitches {
+			rateAt := r.starts[len(r.starts)-r.maxSwitches].Add(time.Hour)
+			if rateAt.After(at) {
+				at = rateAt
+			}
+		}
+	}
+
+	return at
+}
+
+// schedule set the output after duration, or after deferred switch. Lock must be held
+func (r *ProtectedRelayImp) schedule(duration time.Duration, on bool) {
+	if r.pendingState != nil {
+		duration += r.pendingAt.Sub(r.clock.Now())
+	}
+
+	id := r.timerID
+	r.timer = time.AfterFunc(duration, func() {
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+
+		// Timer is canceled or replaced while waiting the lock
+		if id != r.timerID {
+			return
+		}
+		r.timer = nil
+
+		// The end of pulse or OnFor is never rejected, else the relay can stay on forever
+		if err := r.set(on, ProtectionDefer); err != nil {
+			log.Errorf("Error appear when switch relay at the end of timer: %s", err.Error())
+		}
+	})
+}
+
+// cancelPending stop the deferred switch. Lock must be held
+func (r *ProtectedRelayImp) cancelPending() {
+	if r.pending != nil {
+		r.pending.Stop()
+		r.pending = nil
+	}
+	r.pendingState = nil
+}
+
+// cancelTimer stop the pulse or OnFor timer. Lock must be held
+func (r *ProtectedRelayImp) cancelTimer() {
+	if r.timer != nil {
+		r.timer.Stop()
+		r.timer = nil
+	}
+	r.timerID++
+}
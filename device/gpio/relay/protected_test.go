@@ -0,0 +1,128 @@
+package relay
+
+import (
+	"testing"
+	"time"
+
+	"github.com/disaster37/go-arest"
+	"github.com/disaster37/go-arest/device"
+	"github.com/disaster37/go-arest/rest"
+	"github.com/jarcoal/httpmock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProtectedRelay(t *testing.T) {
+	client := rest.MockRestClient()
+	responder := httpmock.NewStringResponder(200, `{}`)
+	for _, url := range []string{"/mode/5/o", "/digital/5/1", "/digital/5/0"} {
+		httpmock.RegisterResponder("POST", "http://localhost"+url, responder)
+	}
+
+	newRelay := func() Relay {
+		signal := arest.NewLevel()
+		signal.SetLevelHigh()
+		output := NewOutput()
+		output.SetOutputNO()
+		defaultState := NewState()
+		defaultState.SetStateOff()
+		relay, err := NewRelay(client, 5, signal, output, defaultState)
+		assert.NoError(t, err)
+		return relay
+	}
+
+	// Bad policy
+	_, err := NewProtectedRelay(newRelay(), 0, 0, 0, "bad")
+	assert.Error(t, err)
+
+	// Reject policy
+	relay, err := NewProtectedRelay(newRelay(), 1*time.Minute, 2*time.Minute, 2, ProtectionReject)
+	assert.NoError(t, err)
+	clock := device.NewFakeClock(time.Now())
+	relay.(*ProtectedRelayImp).SetClock(clock)
+
+	assert.NoError(t, relay.On())
+	assert.Equal(t, true, relay.OutputState().IsOn())
+
+	// Minimum on time
+	clock.Add(30 * time.Second)
+	err = relay.Off()
+	assert.Equal(t, ErrProtected, errors.Cause(err))
+	assert.Equal(t, true, relay.OutputState().IsOn())
+	clock.Add(30 * time.Second)
+	assert.NoError(t, relay.Off())
+	assert.Equal(t, true, relay.OutputState().IsOff())
+
+	// Minimum off time
+	clock.Add(1 * time.Minute)
+	err = relay.Toggle()
+	assert.Equal(t, ErrProtected, errors.Cause(err))
+	clock.Add(1 * time.Minute)
+	assert.NoError(t, relay.Toggle())
+	assert.Equal(t, true, relay.OutputState().IsOn())
+	clock.Add(1 * time.Minute)
+	assert.NoError(t, relay.Off())
+
+	// Maximum switches per hour
+	clock.Add(2 * time.Minute)
+	err = relay.On()
+	assert.Equal(t, ErrProtected, errors.Cause(err))
+	clock.Add(1 * time.Hour)
+	assert.NoError(t, relay.On())
+
+	// Same state is not a switch
+	assert.NoError(t, relay.On())
+	state, _ := relay.Pending()
+	assert.Nil(t, state)
+
+	// Defer policy
+	relay, err = NewProtectedRelay(newRelay(), 100*time.Millisecond, 100*time.Millisecond, 0, ProtectionDefer)
+	assert.NoError(t, err)
+	assert.NoError(t, relay.On())
+	assert.NoError(t, relay.Off())
+	assert.Equal(t, true, relay.OutputState().IsOn())
+	state, at := relay.Pending()
+	assert.Equal(t, true, state.IsOff())
+	assert.WithinDuration(t, time.Now().Add(100*time.Millisecond), at, 50*time.Millisecond)
+	time.Sleep(300 * time.Millisecond)
+	assert.Equal(t, true, relay.OutputState().IsOff())
+	state, _ = relay.Pending()
+	assert.Nil(t, state)
+
+	// Request back to current state cancel the deferred switch
+	assert.NoError(t, relay.On())
+	assert.NoError(t, relay.Off())
+	assert.NoError(t, relay.On())
+	state, _ = relay.Pending()
+	assert.Nil(t, state)
+
+	// Cancel
+	assert.NoError(t, relay.Off())
+	relay.Cancel()
+	time.Sleep(300 * time.Millisecond)
+	assert.Equal(t, true, relay.OutputState().IsOn())
+
+	// OnFor wait the minimum off time, then the minimum on time
+	assert.NoError(t, relay.Off())
+	assert.NoError(t, relay.OnFor(10*time.Millisecond))
+	assert.Equal(t, true, relay.OutputState().IsOff())
+	time.Sleep(150 * time.Millisecond)
+	assert.Equal(t, true, relay.OutputState().IsOn())
+	time.Sleep(250 * time.Millisecond)
+	assert.Equal(t, true, relay.OutputState().IsOff())
+
+	// Reject policy defer the end of OnFor instead of reject it
+	relay, err = NewProtectedRelay(newRelay(), 100*time.Millisecond, 0, 0, ProtectionReject)
+	assert.NoError(t, err)
+	assert.NoError(t, relay.OnFor(20*time.Millisecond))
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, true, relay.OutputState().IsOn())
+	state, _ = relay.Pending()
+	assert.Equal(t, true, state.IsOff())
+	time.Sleep(150 * time.Millisecond)
+	assert.Equal(t, true, relay.OutputState().IsOff())
+
+	// But explicit switch is still rejected
+	assert.NoError(t, relay.On())
+	assert.Equal(t, ErrProtected, errors.Cause(relay.Off()))
+}
@@ -0,0 +1,146 @@
+package relay
+
+import (
+	"context"
+	"time"
+
+	"github.com/disaster37/go-arest"
+	"github.com/disaster37/go-arest/device"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// ErrReadbackMismatch is returned when the level read on pin is not the level written
+var ErrReadbackMismatch = errors.New("Relay pin level not match the written level")
+
+// Drift is the difference between the output state written and the one read back on board
+type Drift struct {
+	Pin       int
+	Expected  State
+	Actual    State
+	Corrected bool
+	Time      time.Time
+}
+
+// SetReadback enable to read back the pin level after each write.
+// When autoCorrect is true, the pin is reconfigured and the level written again on mismatch
+func (r *RelayImp) SetReadback(enabled bool, autoCorrect bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.readback = enabled
+	r.autoCorrect = autoCorrect
+}
+
+// OnDrift add handler called for each mismatch found by read back.
+// Handlers are called without the relay lock
+func (r *RelayImp) OnDrift(handler func(drift *Drift)) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.handlers = append(r.handlers, handler)
+}
+
+// Verify read back the pin level and compare it with the written level.
+// It return ErrReadbackMismatch if they not match and it's not corrected
+func (r *RelayImp) Verify() (err error) {
+	defer r.notify()
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.verify()
+}
+
+// Run verify the pin level periodically until context is done
+func (r *RelayImp) Run(ctx context.Context, interval time.Duration) (err error) {
+	return device.NewPoller().Run(ctx, interval, func() error {
+		if err := r.Verify(); err != nil {
+			return errors.Wrapf(err, "Error when verify relay on pin %d", r.pin)
+		}
+		return nil
+	})
+}
+
+// readbackAfterWrite verify the pin level when read back is enabled. Lock must be held
+// It must be called after the write, so read back error is never mistaken for write error
+func (r *RelayImp) readbackAfterWrite() (err error) {
+	if !r.readback {
+		return nil
+	}
+
+	return r.verify()
+}
+
+// verify read back the pin level. Lock must be held
+func (r *RelayImp) verify() (err error) {
+	if r.level == nil {
+		return nil
+	}
+
+	level, err := r.client.DigitalRead(r.pin)
+	if err != nil {
+		return err
+	}
+	if level.IsHigh() == r.level.IsHigh() {
+		return nil
+	}
+
+	drift := &Drift{
+		Pin:      r.pin,
+		Expected: copyState(r.outputState),
+		Actual:   r.levelToOutputState(level),
+		Time:     time.Now(),
+	}
+	if r.autoCorrect {
+		if err = r.correct(); err != nil {
+			log.Errorf("Error appear when correct relay on pin %d: %s", r.pin, err.Error())
+		} else {
+			drift.Corrected = true
+		}
+	}
+	r.drifts = append(r.drifts, drift)
+
+	if drift.Corrected {
+		return nil
+	}
+
+	return errors.Wrapf(ErrReadbackMismatch, "Relay on pin %d is expected %s but it's %s", r.pin, drift.Expected.State(), drift.Actual.State())
+}
+
+// correct reconfigure the pin and write again the level. Lock must be held
+func (r *RelayImp) correct() (err error) {
+	mode := arest.NewMode()
+	mode.SetModeOutput()
+	if err = r.client.SetPinMode(r.pin, mode); err != nil {
+		return err
+	}
+
+	return r.client.DigitalWrite(r.pin, r.level)
+}
+
+// levelToOutputState return the output state from the pin level
+func (r *RelayImp) levelToOutputState(level arest.Level) State {
+	state := NewState()
+	if level.IsHigh() == (r.output.IsNO() == r.signal.IsHigh()) {
+		state.SetStateOn()
+	} else {
+		state.SetStateOff()
+	}
+
+	return state
+}
+
+// notify call the drift handlers with the pending drifts. Lock must not be held
+func (r *RelayImp) notify() {
+	r.mutex.Lock()
+	drifts := r.drifts
+	handlers := r.handlers
+	r.drifts = make([]*Drift, 0)
+	r.mutex.Unlock()
+
+	for _, drift := range drifts {
+		for _, handler := range handlers {
+			handler(drift)
+		}
+	}
+}
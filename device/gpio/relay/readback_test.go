@@ -0,0 +1,139 @@
+package relay
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/disaster37/go-arest"
+	"github.com/disaster37/go-arest/rest"
+	"github.com/jarcoal/httpmock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRelayReadback(t *testing.T) {
+	client := rest.MockRestClient()
+	responder := httpmock.NewStringResponder(200, `{}`)
+	for _, url := range []string{"/mode/6/o", "/digital/6/1", "/digital/6/0"} {
+		httpmock.RegisterResponder("POST", "http://localhost"+url, responder)
+	}
+	readLevel := func(level int) {
+		httpmock.RegisterResponder("GET", "http://localhost/digital/6", httpmock.NewJsonResponderOrPanic(200, map[string]interface{}{
+			"return_value": level,
+		}))
+	}
+
+	signal := arest.NewLevel()
+	signal.SetLevelHigh()
+	output := NewOutput()
+	output.SetOutputNO()
+	defaultState := NewState()
+	defaultState.SetStateOff()
+	relay, err := NewRelay(client, 6, signal, output, defaultState)
+	assert.NoError(t, err)
+
+	var mutex sync.Mutex
+	drifts := make([]*Drift, 0)
+	relayImp := relay.(*RelayImp)
+	relayImp.OnDrift(func(drift *Drift) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		drifts = append(drifts, drift)
+	})
+	nbDrifts := func() int {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return len(drifts)
+	}
+
+	// Readback is disabled
+	readLevel(0)
+	assert.NoError(t, relay.On())
+	assert.Equal(t, 0, nbDrifts())
+
+	// Readback after write
+	relayImp.SetReadback(true, false)
+	readLevel(1)
+	assert.NoError(t, relay.On())
+	assert.NoError(t, relayImp.Verify())
+	assert.Equal(t, 0, nbDrifts())
+
+	readLevel(0)
+	err = relayImp.Verify()
+	assert.Equal(t, ErrReadbackMismatch, errors.Cause(err))
+	err = relay.Off()
+	assert.NoError(t, err)
+	err = relay.On()
+	assert.Equal(t, ErrReadbackMismatch, errors.Cause(err))
+	assert.Equal(t, true, relay.OutputState().IsOn())
+	assert.Equal(t, 2, nbDrifts())
+	assert.Equal(t, 6, drifts[0].Pin)
+	assert.Equal(t, true, drifts[0].Expected.IsOn())
+	assert.Equal(t, true, drifts[0].Actual.IsOff())
+	assert.Equal(t, false, drifts[0].Corrected)
+
+	// Auto correction
+	relayImp.SetReadback(true, true)
+	httpmock.ZeroCallCounters()
+	assert.NoError(t, relayImp.Verify())
+	assert.Equal(t, 3, nbDrifts())
+	assert.Equal(t, true, drifts[2].Corrected)
+	info := httpmock.GetCallCountInfo()
+	assert.Equal(t, 1, info["POST http://localhost/mode/6/o"])
+	assert.Equal(t, 1, info["POST http://localhost/digital/6/1"])
+
+	// Periodic verification
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	assert.NoError(t, relayImp.Run(ctx, 20*time.Millisecond))
+	assert.True(t, nbDrifts() > 3)
+	assert.Error(t, relayImp.Run(context.Background(), 0))
+}
+
+func TestRelayReadbackTimers(t *testing.T) {
+	client := rest.MockRestClient()
+	responder := httpmock.NewStringResponder(200, `{}`)
+	for _, url := range []string{"/mode/9/o", "/digital/9/1", "/digital/9/0"} {
+		httpmock.RegisterResponder("POST", "http://localhost"+url, responder)
+	}
+
+	// Pin always read back low
+	httpmock.RegisterResponder("GET", "http://localhost/digital/9", httpmock.NewJsonResponderOrPanic(200, map[string]interface{}{
+		"return_value": 0,
+	}))
+
+	signal := arest.NewLevel()
+	signal.SetLevelHigh()
+	output := NewOutput()
+	output.SetOutputNO()
+	defaultState := NewState()
+	defaultState.SetStateOff()
+	relay, err := NewRelay(client, 9, signal, output, defaultState)
+	assert.NoError(t, err)
+	relay.(*RelayImp).SetReadback(true, false)
+
+	// OnFor report the mismatch, but still turn off the relay
+	err = relay.OnFor(20 * time.Millisecond)
+	assert.Equal(t, ErrReadbackMismatch, errors.Cause(err))
+	assert.Equal(t, true, relay.OutputState().IsOn())
+	time.Sleep(80 * time.Millisecond)
+	assert.Equal(t, true, relay.OutputState().IsOff())
+
+	// Pulse report the mismatch, but still restore the relay
+	err = relay.Pulse(20 * time.Millisecond)
+	assert.Equal(t, ErrReadbackMismatch, errors.Cause(err))
+	assert.Equal(t, true, relay.OutputState().IsOn())
+	time.Sleep(80 * time.Millisecond)
+	assert.Equal(t, true, relay.OutputState().IsOff())
+
+	// Read back failure is not write failure
+	httpmock.RegisterResponder("GET", "http://localhost/digital/9", httpmock.NewErrorResponder(http.ErrHandlerTimeout))
+	err = relay.OnFor(20 * time.Millisecond)
+	assert.Error(t, err)
+	assert.Equal(t, true, relay.OutputState().IsOn())
+	time.Sleep(80 * time.Millisecond)
+	assert.Equal(t, true, relay.OutputState().IsOff())
+}
@@ -1,7 +1,12 @@
 package relay
 
 import (
+	"sync"
+	"time"
+
 	"github.com/disaster37/go-arest"
+	"github.com/disaster37/go-arest/device"
+	log "github.com/sirupsen/logrus"
 )
 
 // Relay represent relay device
@@ -13,6 +18,18 @@ type Relay interface {
 	// Off disable the relay output
 	Off() (err error)
 
+	// Toggle invert the relay output
+	Toggle() (err error)
+
+	// Pulse invert the relay output during duration, then restore it
+	Pulse(duration time.Duration) (err error)
+
+	// OnFor enable the relay output during duration, then disable it
+	OnFor(duration time.Duration) (err error)
+
+	// Cancel stop the pending pulse or OnFor timer and keep the current output
+	Cancel()
+
 	// State return the current relay state
 	State() (state State)
 
@@ -24,6 +41,7 @@ type Relay interface {
 }
 
 // RelayImp implement the relay interface
+// It's safe for concurrent use
 type RelayImp struct {
 	pin         int
 	client      arest.Arest
@@ -31,28 +49,188 @@ type RelayImp struct {
 	output      Output
 	state       State
 	outputState State
+	level       arest.Level
+	timer       *time.Timer
+	timerID     uint64
+	timerOn     bool
+	readback    bool
+	autoCorrect bool
+	handlers    []func(drift *Drift)
+	drifts      []*Drift
+	store       device.Store
+	storeKey    string
+	clock       device.Clock
+	accounting  *accounting
+	mutex       sync.Mutex
 }
 
 // NewRelay return new relay object
 func NewRelay(c arest.Arest, pin int, signal arest.Level, output Output, defaultState State) (relay Relay, err error) {
 
-	relay = &RelayImp{
+	relay = newRelay(c, pin, signal, output, defaultState)
+	err = relay.Reset()
+
+	return relay, err
+
+}
+
+func newRelay(c arest.Arest, pin int, signal arest.Level, output Output, defaultState State) *RelayImp {
+	return &RelayImp{
 		client:      c,
 		pin:         pin,
 		signal:      signal,
 		output:      output,
 		state:       NewState(),
 		outputState: defaultState,
+		handlers:    make([]func(drift *Drift), 0),
+		drifts:      make([]*Drift, 0),
+		clock:       device.NewClock(),
 	}
+}
 
-	err = relay.Reset()
+// On enable the relay output
+// It cancel the pending pulse or OnFor timer
+func (r *RelayImp) On() (err error) {
+	defer r.notify()
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
 
-	return relay, err
+	r.cancelTimer()
+	if err = r.on(); err != nil {
+		return err
+	}
 
+	return r.readbackAfterWrite()
 }
 
-// On enable the relay output
-func (r *RelayImp) On() (err error) {
+// Off disable the relay output
+// It cancel the pending pulse or OnFor timer
+func (r *RelayImp) Off() (err error) {
+	defer r.notify()
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.cancelTimer()
+	if err = r.off(); err != nil {
+		return err
+	}
+
+	return r.readbackAfterWrite()
+}
+
+// Toggle invert the relay output
+// It cancel the pending pulse or OnFor timer
+func (r *RelayImp) Toggle() (err error) {
+	defer r.notify()
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.cancelTimer()
+	if r.outputState.IsOn() {
+		err = r.off()
+	} else {
+		err = r.on()
+	}
+	if err != nil {
+		return err
+	}
+
+	return r.readbackAfterWrite()
+}
+
+// Pulse invert the relay output during duration, then restore it
+// It replace the pending pulse or OnFor timer
+func (r *RelayImp) Pulse(duration time.Duration) (err error) {
+	defer r.notify()
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.cancelTimer()
+	restoreOn := r.outputState.IsOn()
+	if restoreOn {
+		err = r.off()
+	} else {
+		err = r.on()
+	}
+	if err != nil {
+		return err
+	}
+
+	// The restore timer is scheduled even if read back failed, so the relay is never left on
+	r.schedule(duration, restoreOn)
+	return r.readbackAfterWrite()
+}
+
+// OnFor enable the relay output during duration, then disable it
+// It replace the pending pulse or OnFor timer
+func (r *RelayImp) OnFor(duration time.Duration) (err error) {
+	defer r.notify()
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.cancelTimer()
+	if err = r.on(); err != nil {
+		return err
+	}
+
+	// The restore timer is scheduled even if read back failed, so the relay is never left on
+	r.schedule(duration, false)
+	return r.readbackAfterWrite()
+}
+
+// Cancel stop the pending pulse or OnFor timer and keep the current output
+func (r *RelayImp) Cancel() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.cancelTimer()
+
+	// The current output is now the state to restore
+	r.save()
+}
+
+// schedule set the output after duration. Lock must be held
+// The output at the end of timer is persisted, so a restart never keep the temporary output
+func (r *RelayImp) schedule(duration time.Duration, on bool) {
+	id := r.timerID
+	r.timerOn = on
+	defer r.save()
+	r.timer = time.AfterFunc(duration, func() {
+		defer r.notify()
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+
+		// Timer is canceled or replaced while waiting the lock
+		if id != r.timerID {
+			return
+		}
+		r.timer = nil
+
+		var err error
+		if on {
+			err = r.on()
+		} else {
+			err = r.off()
+		}
+		if err == nil {
+			err = r.readbackAfterWrite()
+		}
+		if err != nil {
+			log.Errorf("Error appear when switch relay at the end of timer: %s", err.Error())
+		}
+	})
+}
+
+// cancelTimer stop the pending timer. Lock must be held
+func (r *RelayImp) cancelTimer() {
+	if r.timer != nil {
+		r.timer.Stop()
+		r.timer = nil
+	}
+	r.timerID++
+}
+
+func (r *RelayImp) on() (err error) {
 
 	level := arest.NewLevel()
 	state := NewState()
@@ -80,18 +258,20 @@ func (r *RelayImp) On() (err error) {
 		return err
 	}
 
+	r.account(true)
+	r.level = level
 	r.outputState.SetStateOn()
 	if state.IsOn() {
 		r.state.SetStateOn()
 	} else {
 		r.state.SetStateOff()
 	}
+	r.save()
 
 	return nil
 }
 
-// Off disable the relay output
-func (r *RelayImp) Off() (err error) {
+func (r *RelayImp) off() (err error) {
 	level := arest.NewLevel()
 	state := NewState()
 
@@ -118,29 +298,42 @@ func (r *RelayImp) Off() (err error) {
 		return err
 	}
 
+	r.account(false)
+	r.level = level
 	r.outputState.SetStateOff()
 	if state.IsOn() {
 		r.state.SetStateOn()
 	} else {
 		r.state.SetStateOff()
 	}
+	r.save()
 
 	return nil
 }
 
 // State return the current relay state
 func (r *RelayImp) State() State {
-	return r.state
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return copyState(r.state)
 }
 
 // OutputState return the current output state
 func (r *RelayImp) OutputState() State {
-	return r.outputState
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return copyState(r.outputState)
 }
 
 // Reset permit to reconfigure relay. It usefull when board reboot
 // It apply the desired state
 func (r *RelayImp) Reset() (err error) {
+	defer r.notify()
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
 	mode := arest.NewMode()
 	mode.SetModeOutput()
 
@@ -152,11 +345,27 @@ func (r *RelayImp) Reset() (err error) {
 
 	// Set relay on right state
 	if r.outputState.IsOn() {
-		err = r.On()
+		err = r.on()
 	} else {
-		err = r.Off()
+		err = r.off()
+	}
+	if err != nil {
+		return err
 	}
 
-	return err
+	return r.readbackAfterWrite()
+
+}
+
+// copyState return a copy, so the caller can't see next changes
+func copyState(state State) State {
+	s := NewState()
+	switch {
+	case state.IsOn():
+		s.SetStateOn()
+	case state.IsOff():
+		s.SetStateOff()
+	}
 
+	return s
 }
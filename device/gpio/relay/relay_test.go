@@ -1,7 +1,10 @@
 package relay
 
 import (
+	"net/http"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/disaster37/go-arest"
 	"github.com/disaster37/go-arest/rest"
@@ -98,3 +101,116 @@ func TestRelayRest(t *testing.T) {
 	assert.Equal(t, true, relay.OutputState().IsOff())
 
 }
+
+func TestRelayConcurrent(t *testing.T) {
+	client := rest.MockRestClient()
+	responder := httpmock.NewStringResponder(200, `{}`)
+	httpmock.RegisterResponder("POST", "http://localhost/mode/1/o", responder)
+	httpmock.RegisterResponder("POST", "http://localhost/digital/1/1", responder)
+	httpmock.RegisterResponder("POST", "http://localhost/digital/1/0", responder)
+
+	signal := arest.NewLevel()
+	signal.SetLevelHigh()
+	output := NewOutput()
+	output.SetOutputNO()
+	defaultState := NewState()
+	defaultState.SetStateOff()
+	relay, err := NewRelay(client, 1, signal, output, defaultState)
+	assert.NoError(t, err)
+
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				assert.NoError(t, relay.On())
+				assert.NoError(t, relay.Off())
+				assert.NoError(t, relay.Reset())
+				relay.State().IsOn()
+				relay.OutputState().IsOn()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Returned state is a copy
+	state := relay.State()
+	assert.NoError(t, relay.On())
+	assert.Equal(t, true, state.IsOff())
+	assert.Equal(t, true, relay.State().IsOn())
+}
+
+func TestRelayTimers(t *testing.T) {
+	client := rest.MockRestClient()
+	writes := make([]string, 0)
+	mutex := &sync.Mutex{}
+	recorder := func(req *http.Request) (*http.Response, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		writes = append(writes, req.URL.Path)
+		return httpmock.NewStringResponse(200, `{}`), nil
+	}
+	httpmock.RegisterResponder("POST", "http://localhost/mode/2/o", recorder)
+	httpmock.RegisterResponder("POST", "http://localhost/digital/2/1", recorder)
+	httpmock.RegisterResponder("POST", "http://localhost/digital/2/0", recorder)
+	lastWrite := func() string {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return writes[len(writes)-1]
+	}
+
+	// NC and high signal, so output on is low level
+	signal := arest.NewLevel()
+	signal.SetLevelHigh()
+	output := NewOutput()
+	output.SetOutputNC()
+	defaultState := NewState()
+	defaultState.SetStateOff()
+	relay, err := NewRelay(client, 2, signal, output, defaultState)
+	assert.NoError(t, err)
+	assert.Equal(t, "/digital/2/1", lastWrite())
+
+	// Toggle
+	assert.NoError(t, relay.Toggle())
+	assert.Equal(t, true, relay.OutputState().IsOn())
+	assert.Equal(t, "/digital/2/0", lastWrite())
+	assert.NoError(t, relay.Toggle())
+	assert.Equal(t, true, relay.OutputState().IsOff())
+	assert.Equal(t, "/digital/2/1", lastWrite())
+
+	// Pulse from off
+	assert.NoError(t, relay.Pulse(50*time.Millisecond))
+	assert.Equal(t, true, relay.OutputState().IsOn())
+	assert.Equal(t, "/digital/2/0", lastWrite())
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, true, relay.OutputState().IsOff())
+	assert.Equal(t, "/digital/2/1", lastWrite())
+
+	// Pulse from on
+	assert.NoError(t, relay.On())
+	assert.NoError(t, relay.Pulse(50*time.Millisecond))
+	assert.Equal(t, true, relay.OutputState().IsOff())
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, true, relay.OutputState().IsOn())
+
+	// On for duration
+	assert.NoError(t, relay.Off())
+	assert.NoError(t, relay.OnFor(50*time.Millisecond))
+	assert.Equal(t, true, relay.OutputState().IsOn())
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, true, relay.OutputState().IsOff())
+
+	// Explicit on override the pulse
+	assert.NoError(t, relay.Pulse(50*time.Millisecond))
+	assert.NoError(t, relay.On())
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, true, relay.OutputState().IsOn())
+
+	// Cancel keep the current output
+	assert.NoError(t, relay.Off())
+	assert.NoError(t, relay.OnFor(50*time.Millisecond))
+	relay.Cancel()
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, true, relay.OutputState().IsOn())
+}
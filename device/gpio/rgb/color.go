@@ -0,0 +1,126 @@
+package rgb
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Color is the RGB color with optional white channel.
+// White is only used by RGBW led
+type Color struct {
+	Red   uint8
+	Green uint8
+	Blue  uint8
+	White uint8
+}
+
+// Black is the color when led is off
+var Black = Color{}
+
+// NewColor return color from red, green and blue channels
+func NewColor(red uint8, green uint8, blue uint8) Color {
+	return Color{
+		Red:   red,
+		Green: green,
+		Blue:  blue,
+	}
+}
+
+// ParseHex return color from hex string like #RGB, #RRGGBB or #RRGGBBWW.
+// The # is optional
+func ParseHex(hex string) (color Color, err error) {
+	hex = strings.TrimPrefix(hex, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 && len(hex) != 8 {
+		return color, errors.Errorf("Hex color %s must have 3, 6 or 8 digits", hex)
+	}
+
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color, errors.Errorf("Hex color %s is not valid: %s", hex, err.Error())
+	}
+	if len(hex) == 6 {
+		value <<= 8
+	}
+
+	return Color{
+		Red:   uint8(value >> 24),
+		Green: uint8(value >> 16),
+		Blue:  uint8(value >> 8),
+		White: uint8(value),
+	}, nil
+}
+
+// HSV return color from hue (0 - 360), saturation (0 - 1) and value (0 - 1)
+func HSV(hue float64, saturation float64, value float64) (color Color, err error) {
+	if hue < 0 || hue > 360 {
+		return color, errors.Errorf("Hue must be between 0 and 360: %f", hue)
+	}
+	if saturation < 0 || saturation > 1 {
+		return color, errors.Errorf("Saturation must be between 0 and 1: %f", saturation)
+	}
+	if value < 0 || value > 1 {
+		return color, errors.Errorf("Value must be between 0 and 1: %f", value)
+	}
+
+	chroma := value * saturation
+	sector := math.Mod(hue/60, 6)
+	x := chroma * (1 - math.Abs(math.Mod(sector, 2)-1))
+	m := value - chroma
+
+	var r, g, b float64
+	switch {
+	case sector < 1:
+		r, g, b = chroma, x, 0
+	case sector < 2:
+		r, g, b = x, chroma, 0
+	case sector < 3:
+		r, g, b = 0, chroma, x
+	case sector < 4:
+		r, g, b = 0, x, chroma
+	case sector < 5:
+		r, g, b = x, 0, chroma
+	default:
+		r, g, b = chroma, 0, x
+	}
+
+	return NewColor(toChannel(r+m), toChannel(g+m), toChannel(b+m)), nil
+}
+
+// Hex return the color as hex string #RRGGBB, or #RRGGBBWW when white is set
+func (c Color) Hex() string {
+	if c.White != 0 {
+		return fmt.Sprintf("#%02x%02x%02x%02x", c.Red, c.Green, c.Blue, c.White)
+	}
+
+	return fmt.Sprintf("#%02x%02x%02x", c.Red, c.Green, c.Blue)
+}
+
+// String return the color as hex string
+func (c Color) String() string {
+	return c.Hex()
+}
+
+// blend return the color between c (progress 0) and to (progress 1)
+func (c Color) blend(to Color, progress float64) Color {
+	mix := func(from uint8, to uint8) uint8 {
+		return uint8(math.Round(float64(from) + (float64(to)-float64(from))*progress))
+	}
+
+	return Color{
+		Red:   mix(c.Red, to.Red),
+		Green: mix(c.Green, to.Green),
+		Blue:  mix(c.Blue, to.Blue),
+		White: mix(c.White, to.White),
+	}
+}
+
+func toChannel(value float64) uint8 {
+	return uint8(math.Round(value * 255))
+}
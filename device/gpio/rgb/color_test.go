@@ -0,0 +1,61 @@
+package rgb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColor(t *testing.T) {
+
+	// Hex
+	color, err := ParseHex("#ff8000")
+	assert.NoError(t, err)
+	assert.Equal(t, NewColor(255, 128, 0), color)
+	assert.Equal(t, "#ff8000", color.Hex())
+
+	color, err = ParseHex("0f0")
+	assert.NoError(t, err)
+	assert.Equal(t, NewColor(0, 255, 0), color)
+
+	color, err = ParseHex("#10203040")
+	assert.NoError(t, err)
+	assert.Equal(t, Color{Red: 16, Green: 32, Blue: 48, White: 64}, color)
+	assert.Equal(t, "#10203040", color.String())
+
+	_, err = ParseHex("#12345")
+	assert.Error(t, err)
+	_, err = ParseHex("#zzzzzz")
+	assert.Error(t, err)
+
+	// HSV
+	color, err = HSV(0, 1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, NewColor(255, 0, 0), color)
+
+	color, err = HSV(120, 1, 0.5)
+	assert.NoError(t, err)
+	assert.Equal(t, NewColor(0, 128, 0), color)
+
+	color, err = HSV(240, 0.5, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, NewColor(128, 128, 255), color)
+
+	color, err = HSV(360, 0, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, NewColor(255, 255, 255), color)
+
+	_, err = HSV(400, 1, 1)
+	assert.Error(t, err)
+	_, err = HSV(0, 2, 1)
+	assert.Error(t, err)
+	_, err = HSV(0, 1, -1)
+	assert.Error(t, err)
+
+	// Blend
+	from := NewColor(0, 100, 200)
+	to := NewColor(100, 100, 0)
+	assert.Equal(t, from, from.blend(to, 0))
+	assert.Equal(t, NewColor(50, 100, 100), from.blend(to, 0.5))
+	assert.Equal(t, to, from.blend(to, 1))
+}
@@ -0,0 +1,287 @@
+package rgb
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/disaster37/go-arest"
+	"github.com/pkg/errors"
+)
+
+// ErrFadeOverridden is returned by CrossFade when color is changed in the meantime
+var ErrFadeOverridden = errors.New("Fade overridden")
+
+// fadeInterval is the time between two color updates during cross fade
+const fadeInterval = 20 * time.Millisecond
+
+// Led is the RGB / RGBW led interface. It use analog write on PWM pins
+type Led interface {
+
+	// SetColor set the led color
+	SetColor(color Color) error
+
+	// Color return the current color
+	Color() Color
+
+	// TurnOn turn on led with the last color
+	TurnOn() error
+
+	// TurnOff turn off led. The color is kept for the next TurnOn
+	TurnOff() error
+
+	// IsOn return true if led is on
+	IsOn() bool
+
+	// CrossFade change the color progressively during duration
+	CrossFade(ctx context.Context, color Color, duration time.Duration) error
+
+	// SetCalibration set the scale factor (0 - 1) of each channel to balance them
+	SetCalibration(red float64, green float64, blue float64, white float64) error
+
+	// Reset put the led on desired state. It usefull when board reboot
+	Reset() error
+}
+
+// LedImp is the default Led implementation
+// It's safe for concurrent use
+type LedImp struct {
+	client      arest.Arest
+	pins        []int
+	commonAnode bool
+	maxDuty     int
+	calibration []float64
+	color       Color
+	lastColor   Color
+	fade        *runningFade
+	mutex       sync.Mutex
+}
+
+// runningFade is the cross fade currently played
+type runningFade struct {
+	cancel     context.CancelFunc
+	overridden bool
+}
+
+// NewRGB return new RGB led device.
+// Resolution is the board PWM resolution in bits.
+// When commonAnode is true, the PWM duty cycle is inverted
+func NewRGB(client arest.Arest, red int, green int, blue int, resolution int, commonAnode bool) (Led, error) {
+	return newLed(client, []int{red, green, blue}, resolution, commonAnode)
+}
+
+// NewRGBW return new RGBW led device.
+// Resolution is the board PWM resolution in bits.
+// When commonAnode is true, the PWM duty cycle is inverted
+func NewRGBW(client arest.Arest, red int, green int, blue int, white int, resolution int, commonAnode bool) (Led, error) {
+	return newLed(client, []int{red, green, blue, white}, resolution, commonAnode)
+}
+
+func newLed(client arest.Arest, pins []int, resolution int, commonAnode bool) (Led, error) {
+	if resolution < 1 || resolution > 16 {
+		return nil, errors.Errorf("PWM resolution must be between 1 and 16 bits: %d", resolution)
+	}
+
+	calibration := make([]float64, len(pins))
+	for i := range calibration {
+		calibration[i] = 1
+	}
+
+	led := &LedImp{
+		client:      client,
+		pins:        pins,
+		commonAnode: commonAnode,
+		maxDuty:     1<<uint(resolution) - 1,
+		calibration: calibration,
+		lastColor:   Color{Red: 255, Green: 255, Blue: 255},
+	}
+
+	err := led.Reset()
+	if err != nil {
+		return nil, err
+	}
+
+	return led, nil
+}
+
+// SetColor set the led color
+func (h *LedImp) SetColor(color Color) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.overrideFade()
+	if err := h.write(color); err != nil {
+		return err
+	}
+	if h.color != Black {
+		h.lastColor = h.color
+	}
+
+	return nil
+}
+
+// Color return the current color
+func (h *LedImp) Color() Color {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return h.color
+}
+
+// TurnOn turn on led with the last color
+func (h *LedImp) TurnOn() error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.overrideFade()
+	return h.write(h.lastColor)
+}
+
+// TurnOff turn off led. The color is kept for the next TurnOn
+func (h *LedImp) TurnOff() error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.overrideFade()
+	return h.write(Black)
+}
+
+// IsOn return true if led is on
+func (h *LedImp) IsOn() bool {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return h.color != Black
+}
+
+// SetCalibration set the scale factor (0 - 1) of each channel to balance them.
+// White is ignored for RGB led. It's applied on the next color change
+func (h *LedImp) SetCalibration(red float64, green float64, blue float64, white float64) error {
+	calibration := []float64{red, green, blue, white}
+	for _, factor := range calibration {
+		// Negated test, so NaN is rejected too
+		if !(factor >= 0 && factor <= 1) {
+			return errors.Errorf("Calibration factor must be between 0 and 1: %f", factor)
+		}
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.calibration = calibration[:len(h.pins)]
+	return nil
+}
+
+// Reset put the led on desired state. It usefull when board reboot
+func (h *LedImp) Reset() error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.overrideFade()
+	mode := arest.NewMode()
+	mode.SetModeOutput()
+	for _, pin := range h.pins {
+		err := h.client.SetPinMode(pin, mode)
+		if err != nil {
+			return err
+		}
+	}
+
+	return h.write(h.color)
+}
+
+// CrossFade change the color progressively during duration.
+// It replace the running cross fade, and it return ErrFadeOverridden
+// if color is changed in the meantime
+func (h *LedImp) CrossFade(ctx context.Context, color Color, duration time.Duration) (err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	h.mutex.Lock()
+	h.overrideFade()
+	running := &runningFade{
+		cancel: cancel,
+	}
+	h.fade = running
+	from := h.color
+	h.mutex.Unlock()
+
+	err = h.crossFade(ctx, running, from, color, duration)
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if running.overridden {
+		return ErrFadeOverridden
+	}
+	h.fade = nil
+	if err != nil {
+		return err
+	}
+	if h.color != Black {
+		h.lastColor = h.color
+	}
+
+	return nil
+}
+
+func (h *LedImp) crossFade(ctx context.Context, running *runningFade, from Color, to Color, duration time.Duration) (err error) {
+	ticker := time.NewTicker(fadeInterval)
+	defer ticker.Stop()
+	start := time.Now()
+
+	for {
+		progress := 1.0
+		if duration > 0 {
+			progress = math.Min(float64(time.Since(start))/float64(duration), 1)
+		}
+
+		h.mutex.Lock()
+		if running.overridden {
+			h.mutex.Unlock()
+			return nil
+		}
+		err = h.write(from.blend(to, progress))
+		h.mutex.Unlock()
+		if err != nil || progress >= 1 {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// write set the PWM duty cycle of each channel. Lock must be held
+func (h *LedImp) write(color Color) error {
+	if len(h.pins) < 4 {
+		color.White = 0
+	}
+	channels := []uint8{color.Red, color.Green, color.Blue, color.White}
+	for i, pin := range h.pins {
+		duty := int(math.Round(float64(channels[i]) / 255 * h.calibration[i] * float64(h.maxDuty)))
+		if h.commonAnode {
+			duty = h.maxDuty - duty
+		}
+		err := h.client.AnalogWrite(pin, duty)
+		if err != nil {
+			return err
+		}
+	}
+
+	h.color = color
+	return nil
+}
+
+// overrideFade cancel the running cross fade. Lock must be held
+func (h *LedImp) overrideFade() {
+	if h.fade != nil {
+		h.fade.overridden = true
+		h.fade.cancel()
+		h.fade = nil
+	}
+}
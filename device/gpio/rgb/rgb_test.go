@@ -0,0 +1,108 @@
+package rgb
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/disaster37/go-arest/rest"
+	"github.com/jarcoal/httpmock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	prefixed "github.com/x-cray/logrus-prefixed-formatter"
+)
+
+func TestRGB(t *testing.T) {
+	// Init logger
+	logrus.SetFormatter(new(prefixed.TextFormatter))
+	logrus.SetLevel(logrus.DebugLevel)
+
+	client := rest.MockRestClient()
+	duties := make(map[int]int)
+	mutex := &sync.Mutex{}
+	httpmock.RegisterResponder("POST", `=~^http://localhost/mode/\d+/o\z`, httpmock.NewStringResponder(200, `{}`))
+	httpmock.RegisterResponder("POST", `=~^http://localhost/analog/\d+/\d+\z`, func(req *http.Request) (*http.Response, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		parts := strings.Split(req.URL.Path, "/")
+		pin, _ := strconv.Atoi(parts[2])
+		duty, _ := strconv.Atoi(parts[3])
+		duties[pin] = duty
+		return httpmock.NewStringResponse(200, `{}`), nil
+	})
+	channels := func(pins ...int) []int {
+		mutex.Lock()
+		defer mutex.Unlock()
+		values := make([]int, 0, len(pins))
+		for _, pin := range pins {
+			values = append(values, duties[pin])
+		}
+		return values
+	}
+
+	// Bad resolution
+	_, err := NewRGB(client, 3, 5, 6, 0, false)
+	assert.Error(t, err)
+
+	// Common cathode
+	led, err := NewRGB(client, 3, 5, 6, 8, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 0, 0}, channels(3, 5, 6))
+	assert.Equal(t, false, led.IsOn())
+
+	color, _ := ParseHex("#ff8000")
+	assert.NoError(t, led.SetColor(color))
+	assert.Equal(t, []int{255, 128, 0}, channels(3, 5, 6))
+	assert.Equal(t, color, led.Color())
+	assert.Equal(t, true, led.IsOn())
+
+	// Turn off and on restore the last color
+	assert.NoError(t, led.TurnOff())
+	assert.Equal(t, []int{0, 0, 0}, channels(3, 5, 6))
+	assert.NoError(t, led.TurnOn())
+	assert.Equal(t, []int{255, 128, 0}, channels(3, 5, 6))
+
+	// Calibration
+	assert.Error(t, led.SetCalibration(2, 1, 1, 1))
+	assert.Error(t, led.SetCalibration(1, math.NaN(), 1, 1))
+	assert.NoError(t, led.SetCalibration(1, 0.5, 1, 1))
+	assert.NoError(t, led.SetColor(NewColor(255, 255, 255)))
+	assert.Equal(t, []int{255, 128, 255}, channels(3, 5, 6))
+
+	// Common anode on 10 bits with white channel
+	led, err = NewRGBW(client, 9, 10, 11, 12, 10, true)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1023, 1023, 1023, 1023}, channels(9, 10, 11, 12))
+	color, _ = ParseHex("#ff000080")
+	assert.NoError(t, led.SetColor(color))
+	assert.Equal(t, []int{0, 1023, 1023, 509}, channels(9, 10, 11, 12))
+
+	// Cross fade
+	assert.NoError(t, led.SetColor(NewColor(0, 0, 0)))
+	err = led.CrossFade(context.Background(), NewColor(0, 0, 255), 100*time.Millisecond)
+	assert.NoError(t, err)
+	assert.Equal(t, NewColor(0, 0, 255), led.Color())
+	assert.Equal(t, []int{1023, 1023, 0, 1023}, channels(9, 10, 11, 12))
+
+	// Cross fade canceled with context
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = led.CrossFade(ctx, NewColor(255, 0, 0), 1*time.Second)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.NotEqual(t, NewColor(255, 0, 0), led.Color())
+
+	// Set color override cross fade
+	errFade := make(chan error)
+	go func() {
+		errFade <- led.CrossFade(context.Background(), NewColor(0, 255, 0), 1*time.Second)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	assert.NoError(t, led.SetColor(NewColor(10, 10, 10)))
+	assert.Equal(t, ErrFadeOverridden, <-errFade)
+	assert.Equal(t, NewColor(10, 10, 10), led.Color())
+}
@@ -0,0 +1,79 @@
+package device
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// ErrAlreadyRun is returned when poller is run twice
+var ErrAlreadyRun = errors.New("Poller is already run")
+
+// Poller call poll function at interval until context is done.
+// It's shared by devices that poll in background, so they only have to read and emit their events
+// It's safe for concurrent use
+type Poller struct {
+	maxBackoff time.Duration
+	isRun      bool
+	mutex      sync.Mutex
+}
+
+// NewPoller return new poller that never backoff
+func NewPoller() *Poller {
+	return &Poller{}
+}
+
+// SetMaxBackoff permit to wait twice as long after each failed poll, up to max backoff.
+// Max backoff lower than interval disable the backoff
+func (p *Poller) SetMaxBackoff(maxBackoff time.Duration) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.maxBackoff = maxBackoff
+}
+
+// Run call poll at interval until context is done. Error returned by poll is logged.
+// It can be run only once, next calls return ErrAlreadyRun. So when it return nil,
+// the caller can safely release what it share with poll, like closing its events channel
+func (p *Poller) Run(ctx context.Context, interval time.Duration, poll func() error) (err error) {
+	if interval <= 0 {
+		return errors.Errorf("Interval must be positive: %s", interval)
+	}
+
+	p.mutex.Lock()
+	if p.isRun {
+		p.mutex.Unlock()
+		return ErrAlreadyRun
+	}
+	p.isRun = true
+	maxBackoff := p.maxBackoff
+	p.mutex.Unlock()
+
+	if maxBackoff < interval {
+		maxBackoff = interval
+	}
+
+	wait := interval
+	for {
+		if err = poll(); err != nil {
+			log.Errorf("Error appear when poll device: %s", err.Error())
+			wait *= 2
+			if wait > maxBackoff {
+				wait = maxBackoff
+			}
+		} else {
+			wait = interval
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package device
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPoller(t *testing.T) {
+	var nbPoll int32
+	poll := func() error {
+		atomic.AddInt32(&nbPoll, 1)
+		return nil
+	}
+
+	// Bad interval
+	poller := NewPoller()
+	assert.Error(t, poller.Run(context.Background(), 0, poll))
+
+	// Poll at interval until context is done
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	assert.NoError(t, poller.Run(ctx, 10*time.Millisecond, poll))
+	assert.True(t, atomic.LoadInt32(&nbPoll) >= 5)
+
+	// Already run
+	assert.Equal(t, ErrAlreadyRun, poller.Run(context.Background(), 10*time.Millisecond, poll))
+}
+
+func TestPollerBackoff(t *testing.T) {
+	var nbPoll int32
+	poll := func() error {
+		atomic.AddInt32(&nbPoll, 1)
+		return errors.New("Poll failed")
+	}
+
+	// 10ms, 20ms, 40ms, 40ms ...
+	poller := NewPoller()
+	poller.SetMaxBackoff(40 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 150*time.Millisecond)
+	defer cancel()
+	assert.NoError(t, poller.Run(ctx, 10*time.Millisecond, poll))
+	assert.True(t, atomic.LoadInt32(&nbPoll) <= 6)
+
+	// Max backoff lower than interval disable the backoff
+	atomic.StoreInt32(&nbPoll, 0)
+	poller = NewPoller()
+	poller.SetMaxBackoff(time.Millisecond)
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	assert.NoError(t, poller.Run(ctx, 10*time.Millisecond, poll))
+	assert.True(t, atomic.LoadInt32(&nbPoll) >= 5)
+}
@@ -0,0 +1,210 @@
+package device
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Store is key / value store to persist devices states across process restarts
+// Values are encoded in JSON
+type Store interface {
+
+	// Load decode the value of key. It return false if key not exist
+	Load(key string, value interface{}) (found bool, err error)
+
+	// Save encode and store the value of key
+	Save(key string, value interface{}) (err error)
+
+	// Delete remove the key
+	Delete(key string) (err error)
+}
+
+// MemoryStore is the in-memory Store implementation
+// It's safe for concurrent use
+type MemoryStore struct {
+	values map[string][]byte
+	mutex  sync.Mutex
+}
+
+// FileStore is the Store implementation that keep all keys in one JSON file
+// The file is written on each change
+// It's safe for concurrent use
+type FileStore struct {
+	path  string
+	mutex sync.Mutex
+}
+
+// NewMemoryStore return new empty in-memory store
+func NewMemoryStore() Store {
+	return &MemoryStore{
+		values: make(map[string][]byte),
+	}
+}
+
+// NewFileStore return new store backed by the JSON file.
+// The file is created on the first save
+func NewFileStore(path string) Store {
+	return &FileStore{
+		path: path,
+	}
+}
+
+// Load decode the value of key. It return false if key not exist
+func (s *MemoryStore) Load(key string, value interface{}) (bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	data, ok := s.values[key]
+	if !ok {
+		return false, nil
+	}
+
+	return true, json.Unmarshal(data, value)
+}
+
+// Save encode and store the value of key
+func (s *MemoryStore) Save(key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.values[key] = data
+	return nil
+}
+
+// Delete remove the key
+func (s *MemoryStore) Delete(key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.values, key)
+	return nil
+}
+
+// Load decode the value of key. It return false if key not exist
+func (s *FileStore) Load(key string, value interface{}) (bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	values, err := s.read()
+	if err != nil {
+		return false, err
+	}
+	data, ok := values[key]
+	if !ok {
+		return false, nil
+	}
+
+	return true, json.Unmarshal(data, value)
+}
+
+// Save encode and store the value of key
+func (s *FileStore) Save(key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	values, err := s.read()
+	if err != nil {
+		return err
+	}
+	values[key] = data
+
+	return s.write(values)
+}
+
+// Delete remove the key
+func (s *FileStore) Delete(key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	values, err := s.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := values[key]; !ok {
+		return nil
+	}
+	delete(values, key)
+
+	return s.write(values)
+}
+
+// read load all keys from file. Lock must be held
+func (s *FileStore) read() (map[string]json.RawMessage, error) {
+	values := make(map[string]json.RawMessage)
+	data, err := ioutil.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return values, nil
+		}
+		return nil, err
+	}
+
+	if err = json.Unmarshal(data, &values); err != nil {
+		return nil, errors.Wrapf(err, "Store file %s is corrupted", s.path)
+	}
+
+	return values, nil
+}
+
+// write replace the file with all keys. Lock must be held
+// It write on temporary file before rename it, so the file is never partially written.
+// The file and its directory are synced, so the file is not lost or empty after power loss
+func (s *FileStore) write(values map[string]json.RawMessage) error {
+	data, err := json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(s.path)
+	tmp, err := ioutil.TempFile(dir, filepath.Base(s.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if err = os.Rename(tmp.Name(), s.path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return syncDir(dir)
+}
+
+// syncDir flush the directory entries, so the rename is durable
+func syncDir(dir string) error {
+	f, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return f.Sync()
+}
@@ -0,0 +1,64 @@
+package device
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testStore(t *testing.T, store Store) {
+	var value map[string]int
+
+	// Key not exist
+	found, err := store.Load("test", &value)
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	// Save and load
+	assert.NoError(t, store.Save("test", map[string]int{"a": 1}))
+	assert.NoError(t, store.Save("other", "value"))
+	found, err = store.Load("test", &value)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, map[string]int{"a": 1}, value)
+
+	// Delete
+	assert.NoError(t, store.Delete("test"))
+	assert.NoError(t, store.Delete("test"))
+	found, err = store.Load("test", &value)
+	assert.NoError(t, err)
+	assert.False(t, found)
+	var other string
+	found, err = store.Load("other", &other)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "value", other)
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
+
+func TestFileStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "store")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "state.json")
+
+	testStore(t, NewFileStore(path))
+
+	// Values survive new store
+	var other string
+	found, err := NewFileStore(path).Load("other", &other)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "value", other)
+
+	// Corrupted file
+	assert.NoError(t, ioutil.WriteFile(path, []byte("{"), 0644))
+	_, err = NewFileStore(path).Load("other", &other)
+	assert.Error(t, err)
+}
@@ -0,0 +1,245 @@
+package variable
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/disaster37/go-arest"
+	"github.com/disaster37/go-arest/device"
+	"github.com/pkg/errors"
+)
+
+// Common units
+const (
+	UnitCelsius     string = "°C"
+	UnitFahrenheit  string = "°F"
+	UnitPercent     string = "%"
+	UnitHectopascal string = "hPa"
+	UnitPascal      string = "Pa"
+	UnitMeter       string = "m"
+	UnitLux         string = "lx"
+	UnitVolt        string = "V"
+)
+
+var (
+	// ErrStale is returned when the last reading is older than the max age
+	ErrStale = errors.New("Reading is stale")
+
+	// ErrNotRead is returned when the variable is not read yet
+	ErrNotRead = errors.New("Variable is not read yet")
+)
+
+// Variable is the aREST variable binding.
+// The value is raw * Scale + Offset. Scale 0 is the zero value, so it's handled as 1
+type Variable struct {
+	Name   string
+	Unit   string
+	Scale  float64
+	Offset float64
+}
+
+// Reading is the value of variable at read time
+type Reading struct {
+	Name  string
+	Value float64
+	Unit  string
+	Time  time.Time
+}
+
+// Sensor is sensor bound to one or more aREST variables
+type Sensor interface {
+
+	// Read fetch all variables in one round trip and return the readings by variable name
+	Read() (readings map[string]Reading, err error)
+
+	// Reading return the last reading of variable.
+	// It return ErrNotRead if variable is not read yet, and ErrStale if reading is older than max age
+	Reading(name string) (reading Reading, err error)
+
+	// Value return the last value of variable
+	Value(name string) (value float64, err error)
+
+	// IsStale return true if one variable is not read since max age
+	IsStale() bool
+}
+
+// SensorImp is the default Sensor implementation
+// It's safe for concurrent use
+type SensorImp struct {
+	client    arest.Arest
+	maxAge    time.Duration
+	variables map[string]Variable
+	names     []string
+	readings  map[string]Reading
+	clock     device.Clock
+	mutex     sync.Mutex
+}
+
+// NewVariable return variable binding without scaling
+func NewVariable(name string, unit string) Variable {
+	return Variable{
+		Name:  name,
+		Unit:  unit,
+		Scale: 1,
+	}
+}
+
+// NewSensor return new sensor bound to variables.
+// Readings older than max age are stale, 0 disable staleness detection
+// Variables without scale are not scaled
+func NewSensor(client arest.Arest, maxAge time.Duration, variables ...Variable) (Sensor, error) {
+	if len(variables) == 0 {
+		return nil, errors.New("Sensor need at least one variable")
+	}
+
+	bindings := make(map[string]Variable, len(variables))
+	names := make([]string, 0, len(variables))
+	for _, variable := range variables {
+		if variable.Name == "" {
+			return nil, errors.New("Variable name can't be empty")
+		}
+		if _, ok := bindings[variable.Name]; ok {
+			return nil, errors.Errorf("Variable %s is bound twice", variable.Name)
+		}
+		if variable.Scale == 0 {
+			variable.Scale = 1
+		}
+		bindings[variable.Name] = variable
+		names = append(names, variable.Name)
+	}
+	sort.Strings(names)
+
+	return &SensorImp{
+		client:    client,
+		maxAge:    maxAge,
+		variables: bindings,
+		names:     names,
+		readings:  make(map[string]Reading, len(variables)),
+		clock:     device.NewClock(),
+	}, nil
+}
+
+// SetClock permit to use custom clock
+func (h *SensorImp) SetClock(clock device.Clock) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.clock = clock
+}
+
+// Read fetch all variables in one round trip and return the readings by variable name.
+// Variables found are updated even if other are missing or not numeric
+func (h *SensorImp) Read() (readings map[string]Reading, err error) {
+	values, err := h.client.ReadValues()
+	if err != nil {
+		return nil, err
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	now := h.clock.Now()
+	readings = make(map[string]Reading, len(h.names))
+	failed := make([]string, 0)
+	for _, name := range h.names {
+		variable := h.variables[name]
+		raw, ok := values[name]
+		if !ok {
+			failed = append(failed, name+" not found")
+			continue
+		}
+		value, err := toFloat(raw)
+		if err != nil {
+			failed = append(failed, name+" "+err.Error())
+			continue
+		}
+
+		reading := Reading{
+			Name:  name,
+			Value: value*variable.Scale + variable.Offset,
+			Unit:  variable.Unit,
+			Time:  now,
+		}
+		h.readings[name] = reading
+		readings[name] = reading
+	}
+
+	if len(failed) > 0 {
+		return readings, errors.Errorf("Error when read variables: %s", strings.Join(failed, ", "))
+	}
+
+	return readings, nil
+}
+
+// Reading return the last reading of variable.
+// It return ErrNotRead if variable is not read yet, and ErrStale if reading is older than max age
+func (h *SensorImp) Reading(name string) (reading Reading, err error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if _, ok := h.variables[name]; !ok {
+		return reading, errors.Errorf("Variable %s is not bound to sensor", name)
+	}
+	reading, ok := h.readings[name]
+	if !ok {
+		return reading, errors.Wrapf(ErrNotRead, "Variable %s", name)
+	}
+	if h.isStale(reading) {
+		return reading, errors.Wrapf(ErrStale, "Variable %s is read at %s", name, reading.Time.Format(time.RFC3339))
+	}
+
+	return reading, nil
+}
+
+// Value return the last value of variable
+func (h *SensorImp) Value(name string) (value float64, err error) {
+	reading, err := h.Reading(name)
+	return reading.Value, err
+}
+
+// IsStale return true if one variable is not read since max age
+func (h *SensorImp) IsStale() bool {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	for _, name := range h.names {
+		reading, ok := h.readings[name]
+		if !ok || h.isStale(reading) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isStale return true if reading is older than max age. Lock must be held
+func (h *SensorImp) isStale(reading Reading) bool {
+	return h.maxAge > 0 && h.clock.Now().Sub(reading.Time) > h.maxAge
+}
+
+// toFloat convert the aREST variable value to float
+func toFloat(raw interface{}) (float64, error) {
+	switch value := raw.(type) {
+	case float64:
+		return value, nil
+	case float32:
+		return float64(value), nil
+	case int:
+		return float64(value), nil
+	case int64:
+		return float64(value), nil
+	case string:
+		// Sensor sketches report failed read as nan, so it's not a value
+		number, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+			return 0, errors.Errorf("is not numeric: %s", value)
+		}
+		return number, nil
+	default:
+		return 0, errors.Errorf("is not numeric: %v", raw)
+	}
+}
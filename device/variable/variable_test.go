@@ -0,0 +1,105 @@
+package variable
+
+import (
+	"testing"
+	"time"
+
+	"github.com/disaster37/go-arest/device"
+	"github.com/disaster37/go-arest/rest"
+	"github.com/jarcoal/httpmock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSensor(t *testing.T) {
+	client := rest.MockRestClient()
+	readVariables := func(variables map[string]interface{}) {
+		httpmock.RegisterResponder("GET", "http://localhost/", httpmock.NewJsonResponderOrPanic(200, map[string]interface{}{
+			"variables": variables,
+		}))
+	}
+
+	// Bad bindings
+	_, err := NewSensor(client, time.Minute)
+	assert.Error(t, err)
+	_, err = NewSensor(client, time.Minute, NewVariable("", UnitCelsius))
+	assert.Error(t, err)
+	_, err = NewSensor(client, time.Minute, NewVariable("temperature", UnitCelsius), NewVariable("temperature", UnitCelsius))
+	assert.Error(t, err)
+
+	// Pressure is sent in Pa and reported in hPa
+	sensor, err := NewSensor(client, time.Minute,
+		NewVariable("temperature", UnitCelsius),
+		NewVariable("humidity", UnitPercent),
+		Variable{Name: "pressure", Unit: UnitHectopascal, Scale: 0.01},
+		Variable{Name: "altitude", Unit: UnitMeter},
+	)
+	assert.NoError(t, err)
+	clock := device.NewFakeClock(time.Now())
+	sensor.(*SensorImp).SetClock(clock)
+
+	// Not read yet
+	_, err = sensor.Value("temperature")
+	assert.Equal(t, ErrNotRead, errors.Cause(err))
+	_, err = sensor.Value("bad")
+	assert.Error(t, err)
+	assert.True(t, sensor.IsStale())
+
+	// All variables in one round trip
+	readVariables(map[string]interface{}{
+		"temperature": 21.5,
+		"humidity":    "45.2",
+		"pressure":    101325,
+		"altitude":    250,
+		"other":       true,
+	})
+	httpmock.ZeroCallCounters()
+	readings, err := sensor.Read()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, httpmock.GetTotalCallCount())
+	assert.Equal(t, Reading{Name: "temperature", Value: 21.5, Unit: UnitCelsius, Time: clock.Now()}, readings["temperature"])
+	value, err := sensor.Value("humidity")
+	assert.NoError(t, err)
+	assert.Equal(t, 45.2, value)
+	reading, err := sensor.Reading("pressure")
+	assert.NoError(t, err)
+	assert.InDelta(t, 1013.25, reading.Value, 0.001)
+	assert.Equal(t, UnitHectopascal, reading.Unit)
+	// Variable without scale is not scaled
+	value, err = sensor.Value("altitude")
+	assert.NoError(t, err)
+	assert.Equal(t, 250.0, value)
+	assert.False(t, sensor.IsStale())
+
+	// Missing and not numeric variables
+	clock.Add(30 * time.Second)
+	readVariables(map[string]interface{}{
+		"temperature": 22,
+		"humidity":    "nan%",
+	})
+	readings, err = sensor.Read()
+	assert.Error(t, err)
+	assert.Equal(t, 1, len(readings))
+	value, err = sensor.Value("temperature")
+	assert.NoError(t, err)
+	assert.Equal(t, 22.0, value)
+
+	// Not finite variables, like sketch that failed to read its sensor
+	readVariables(map[string]interface{}{
+		"temperature": 22,
+		"humidity":    "nan",
+		"pressure":    "+Inf",
+	})
+	readings, err = sensor.Read()
+	assert.Error(t, err)
+	assert.Equal(t, 1, len(readings))
+
+	// Staleness
+	clock.Add(45 * time.Second)
+	assert.True(t, sensor.IsStale())
+	value, err = sensor.Value("humidity")
+	assert.Equal(t, ErrStale, errors.Cause(err))
+	assert.Equal(t, 45.2, value)
+	_, err = sensor.Value("temperature")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,171 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/disaster37/go-arest"
+	"github.com/disaster37/go-arest/device"
+	"github.com/pkg/errors"
+)
+
+// Board states
+const (
+	StateUnknown  string = "unknown"
+	StateUp       string = "up"
+	StateDegraded string = "degraded"
+	StateDown     string = "down"
+)
+
+// Monitor probe board and track its liveness
+type Monitor interface {
+
+	// SetHeartbeatVariable permit to probe variable instead of root info
+	SetHeartbeatVariable(name string)
+
+	// OnChange add handler called when board state change
+	OnChange(handler func(previous Status, current Status))
+
+	// Probe check the board now and return the new status
+	Probe() Status
+
+	// Status return the current status
+	Status() Status
+
+	// Run probe the board at interval until context is done
+	Run(ctx context.Context, interval time.Duration) (err error)
+}
+
+// Status is the board liveness status
+type Status struct {
+	State               string
+	Latency             time.Duration
+	ConsecutiveFailures int
+	LastCheck           time.Time
+	LastError           error
+}
+
+// MonitorImp implement the monitor interface
+type MonitorImp struct {
+	client            arest.Arest
+	heartbeatVariable string
+	failureThreshold  int
+	latencyThreshold  time.Duration
+	status            Status
+	handlers          []func(previous Status, current Status)
+	mutex             sync.Mutex
+}
+
+// NewMonitor return new monitor object
+// Board is down after failureThreshold consecutive failures, and degraded before.
+// It's also degraded when probe take more than latencyThreshold
+func NewMonitor(client arest.Arest, failureThreshold int, latencyThreshold time.Duration) Monitor {
+	return &MonitorImp{
+		client:           client,
+		failureThreshold: failureThreshold,
+		latencyThreshold: latencyThreshold,
+		status: Status{
+			State: StateUnknown,
+		},
+		handlers: make([]func(previous Status, current Status), 0),
+	}
+}
+
+// SetHeartbeatVariable permit to probe variable instead of root info
+func (h *MonitorImp) SetHeartbeatVariable(name string) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.heartbeatVariable = name
+}
+
+// OnChange add handler called when board state change
+func (h *MonitorImp) OnChange(handler func(previous Status, current Status)) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.handlers = append(h.handlers, handler)
+}
+
+// Status return the current status
+func (h *MonitorImp) Status() Status {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return h.status
+}
+
+// Probe check the board now and return the new status
+func (h *MonitorImp) Probe() Status {
+	var err error
+	h.mutex.Lock()
+	heartbeatVariable := h.heartbeatVariable
+	h.mutex.Unlock()
+
+	start := time.Now()
+	if heartbeatVariable != "" {
+		_, err = h.client.ReadValue(heartbeatVariable)
+	} else {
+		_, err = h.client.ReadValues()
+	}
+	latency := time.Since(start)
+
+	h.mutex.Lock()
+	previous := h.status
+	current := Status{
+		Latency:   latency,
+		LastCheck: start,
+		LastError: err,
+	}
+	if err != nil {
+		current.ConsecutiveFailures = previous.ConsecutiveFailures + 1
+		if current.ConsecutiveFailures >= h.failureThreshold {
+			current.State = StateDown
+		} else {
+			current.State = StateDegraded
+		}
+	} else if h.latencyThreshold > 0 && latency > h.latencyThreshold {
+		current.State = StateDegraded
+	} else {
+		current.State = StateUp
+	}
+	h.status = current
+	handlers := h.handlers
+	h.mutex.Unlock()
+
+	if current.State != previous.State {
+		arest.Debug("Board state change from %s to %s", previous.State, current.State)
+		for _, handler := range handlers {
+			handler(previous, current)
+		}
+	}
+
+	return current
+}
+
+// Run probe the board at interval until context is done
+func (h *MonitorImp) Run(ctx context.Context, interval time.Duration) (err error) {
+	return device.NewPoller().Run(ctx, interval, func() error {
+		status := h.Probe()
+		if status.LastError != nil {
+			return errors.Wrap(status.LastError, "Error when probe board")
+		}
+		return nil
+	})
+}
+
+// IsUp return true if board is up
+func (s Status) IsUp() bool {
+	return s.State == StateUp
+}
+
+// IsDegraded return true if board is degraded
+func (s Status) IsDegraded() bool {
+	return s.State == StateDegraded
+}
+
+// IsDown return true if board is down
+func (s Status) IsDown() bool {
+	return s.State == StateDown
+}
@@ -0,0 +1,81 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/disaster37/go-arest/rest"
+	"github.com/jarcoal/httpmock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	prefixed "github.com/x-cray/logrus-prefixed-formatter"
+)
+
+func TestMonitor(t *testing.T) {
+	// Init logger
+	logrus.SetFormatter(new(prefixed.TextFormatter))
+	logrus.SetLevel(logrus.DebugLevel)
+
+	var delay time.Duration
+	responder := func(req *http.Request) (*http.Response, error) {
+		time.Sleep(delay)
+		return httpmock.NewJsonResponse(200, map[string]interface{}{
+			"variables": map[string]interface{}{
+				"heartbeat": 1,
+			},
+			"heartbeat": 1,
+		})
+	}
+	httpmock.RegisterResponder("GET", "http://localhost/", responder)
+
+	client := rest.MockRestClient()
+	monitor := NewMonitor(client, 3, 100*time.Millisecond)
+	changes := make([]string, 0)
+	monitor.OnChange(func(previous Status, current Status) {
+		changes = append(changes, previous.State+"->"+current.State)
+	})
+	assert.Equal(t, StateUnknown, monitor.Status().State)
+
+	// Up
+	status := monitor.Probe()
+	assert.True(t, status.IsUp())
+	assert.NoError(t, status.LastError)
+	assert.False(t, status.LastCheck.IsZero())
+
+	// Degraded with latency
+	delay = 150 * time.Millisecond
+	status = monitor.Probe()
+	assert.True(t, status.IsDegraded())
+	assert.True(t, status.Latency >= delay)
+	delay = 0
+
+	// Degraded and down with failures
+	httpmock.Reset()
+	status = monitor.Probe()
+	assert.True(t, status.IsDegraded())
+	assert.Error(t, status.LastError)
+	assert.Equal(t, 1, status.ConsecutiveFailures)
+	monitor.Probe()
+	status = monitor.Probe()
+	assert.True(t, status.IsDown())
+	assert.Equal(t, 3, status.ConsecutiveFailures)
+
+	// Up again with heartbeat variable
+	httpmock.RegisterResponder("GET", "http://localhost/heartbeat", responder)
+	monitor.SetHeartbeatVariable("heartbeat")
+	status = monitor.Probe()
+	assert.True(t, status.IsUp())
+	assert.Equal(t, 0, status.ConsecutiveFailures)
+	assert.Equal(t, []string{"unknown->up", "up->degraded", "degraded->down", "down->up"}, changes)
+
+	// Run until context is done
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.NoError(t, monitor.Run(ctx, 10*time.Millisecond))
+	assert.True(t, monitor.Status().IsUp())
+
+	// Bad interval
+	assert.Error(t, monitor.Run(context.Background(), 0))
+}
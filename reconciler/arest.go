@@ -0,0 +1,357 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/disaster37/go-arest"
+	"github.com/disaster37/go-arest/device"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// Client implement arest interface on top of other client
+// It keep the desired mode, level and analog value of each pin configured through it,
+// and re-apply them when it detect that the board reboot
+type Client struct {
+	client         arest.Arest
+	pins           map[int]*pinState
+	uptimeVariable string
+	idVariable     string
+	lastUptime     float64
+	lastID         string
+	disconnected   bool
+	rebootHandlers []func()
+	driftHandlers  []func(drift *Drift)
+	mutex          sync.Mutex
+}
+
+// Drift is the difference between desired level and the level read on board
+type Drift struct {
+	Pin     int
+	Desired arest.Level
+	Actual  arest.Level
+}
+
+type pinState struct {
+	mode   arest.Mode
+	level  arest.Level
+	analog *int
+}
+
+// NewClient permit to initialize new reconciler client Object
+func NewClient(client arest.Arest) arest.Arest {
+	return &Client{
+		client:         client,
+		pins:           make(map[int]*pinState),
+		rebootHandlers: make([]func(), 0),
+		driftHandlers:  make([]func(drift *Drift), 0),
+	}
+}
+
+// Client permit to get the decorated client
+func (c *Client) Client() arest.Arest {
+	return c.client
+}
+
+// SetUptimeVariable permit to detect reboot when the uptime variable decrease
+func (c *Client) SetUptimeVariable(name string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.uptimeVariable = name
+}
+
+// SetIDVariable permit to detect reboot when the id variable change
+func (c *Client) SetIDVariable(name string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.idVariable = name
+}
+
+// OnReboot add handler called when reboot is detected, before re-apply the configuration
+func (c *Client) OnReboot(handler func()) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.rebootHandlers = append(c.rebootHandlers, handler)
+}
+
+// OnDrift add handler called for each drift found by Check
+func (c *Client) OnDrift(handler func(drift *Drift)) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.driftHandlers = append(c.driftHandlers, handler)
+}
+
+// SetPinMode permit to set pin mode and keep it as desired mode
+func (c *Client) SetPinMode(pin int, mode arest.Mode) (err error) {
+	c.mutex.Lock()
+	c.pin(pin).mode = copyMode(mode)
+	c.mutex.Unlock()
+
+	return c.client.SetPinMode(pin, mode)
+}
+
+// DigitalWrite permit to set level on pin and keep it as desired level
+func (c *Client) DigitalWrite(pin int, level arest.Level) (err error) {
+	c.mutex.Lock()
+	c.pin(pin).level = copyLevel(level)
+	c.pin(pin).analog = nil
+	c.mutex.Unlock()
+
+	return c.client.DigitalWrite(pin, level)
+}
+
+// DigitalRead permit to read level from pin
+func (c *Client) DigitalRead(pin int) (level arest.Level, err error) {
+	return c.client.DigitalRead(pin)
+}
+
+// AnalogRead permit to read analog value from pin
+func (c *Client) AnalogRead(pin int) (value int, err error) {
+	return c.client.AnalogRead(pin)
+}
+
+// AnalogWrite permit to set analog value on pin and keep it as desired value
+func (c *Client) AnalogWrite(pin int, value int) (err error) {
+	c.mutex.Lock()
+	c.pin(pin).analog = &value
+	c.pin(pin).level = nil
+	c.mutex.Unlock()
+
+	return c.client.AnalogWrite(pin, value)
+}
+
+// ReadValue permit to read user variable
+func (c *Client) ReadValue(name string) (value interface{}, err error) {
+	return c.client.ReadValue(name)
+}
+
+// ReadValues permit to read all user variables
+func (c *Client) ReadValues() (values map[string]interface{}, err error) {
+	return c.client.ReadValues()
+}
+
+// CallFunction permit to call user function
+func (c *Client) CallFunction(name string, param string) (resp int, err error) {
+	return c.client.CallFunction(name, param)
+}
+
+// Reconcile re-apply the desired mode, level and analog value of all pins
+func (c *Client) Reconcile() (err error) {
+	c.mutex.Lock()
+	pins := c.sortedPins()
+	states := make([]pinState, 0, len(pins))
+	for _, pin := range pins {
+		states = append(states, *c.pins[pin])
+	}
+	c.mutex.Unlock()
+
+	for i, pin := range pins {
+		if states[i].mode != nil {
+			if err = c.client.SetPinMode(pin, states[i].mode); err != nil {
+				return err
+			}
+		}
+		if states[i].level != nil {
+			if err = c.client.DigitalWrite(pin, states[i].level); err != nil {
+				return err
+			}
+		}
+		if states[i].analog != nil {
+			if err = c.client.AnalogWrite(pin, *states[i].analog); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Drifts read back all output pins and return the ones that not have the desired level
+func (c *Client) Drifts() (drifts []*Drift, err error) {
+	c.mutex.Lock()
+	pins := c.sortedPins()
+	desired := make(map[int]arest.Level, len(pins))
+	for _, pin := range pins {
+		if c.pins[pin].level != nil {
+			desired[pin] = c.pins[pin].level
+		}
+	}
+	c.mutex.Unlock()
+
+	drifts = make([]*Drift, 0)
+	for _, pin := range pins {
+		level, ok := desired[pin]
+		if !ok {
+			continue
+		}
+		actual, err := c.client.DigitalRead(pin)
+		if err != nil {
+			return nil, err
+		}
+		if actual.Level() != level.Level() {
+			drifts = append(drifts, &Drift{
+				Pin:     pin,
+				Desired: level,
+				Actual:  actual,
+			})
+		}
+	}
+
+	return drifts, nil
+}
+
+// Check detect if board reboot and re-apply configuration if needed.
+// The board is handled as rebooted when the previous check failed to reach it.
+// Then it read back output pins and notify drift handlers
+func (c *Client) Check() (drifts []*Drift, err error) {
+	rebooted, err := c.isRebooted()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mutex.Lock()
+	rebootHandlers := c.rebootHandlers
+	driftHandlers := c.driftHandlers
+	c.mutex.Unlock()
+
+	if rebooted {
+		log.Infof("Board reboot detected, re-apply pins configuration")
+		for _, handler := range rebootHandlers {
+			handler()
+		}
+		if err = c.Reconcile(); err != nil {
+			// Configuration will be re-applied on the next check
+			c.mutex.Lock()
+			c.disconnected = true
+			c.mutex.Unlock()
+			return nil, err
+		}
+	}
+
+	drifts, err = c.Drifts()
+	if err != nil {
+		return nil, err
+	}
+	for _, drift := range drifts {
+		for _, handler := range driftHandlers {
+			handler(drift)
+		}
+	}
+
+	return drifts, nil
+}
+
+// Run check the board at interval until context is done
+func (c *Client) Run(ctx context.Context, interval time.Duration) (err error) {
+	return device.NewPoller().Run(ctx, interval, func() error {
+		if _, err := c.Check(); err != nil {
+			return errors.Wrap(err, "Error when check board")
+		}
+		return nil
+	})
+}
+
+// isRebooted fetch variables and compare them with the previous ones
+// When variables can't be fetched, the board is unreachable and it can reboot meanwhile
+func (c *Client) isRebooted() (rebooted bool, err error) {
+	values, err := c.client.ReadValues()
+	if err != nil {
+		c.mutex.Lock()
+		c.disconnected = true
+		c.mutex.Unlock()
+		return false, err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	// Connection come back after failure
+	if c.disconnected {
+		rebooted = true
+		c.disconnected = false
+	}
+
+	if c.uptimeVariable != "" {
+		temp, ok := values[c.uptimeVariable]
+		if !ok {
+			return false, errors.Errorf("Variable %s not found", c.uptimeVariable)
+		}
+		uptime, ok := temp.(float64)
+		if !ok {
+			return false, errors.Errorf("Variable %s is not a number: %v", c.uptimeVariable, temp)
+		}
+		if uptime < c.lastUptime {
+			rebooted = true
+		}
+		c.lastUptime = uptime
+	}
+
+	if c.idVariable != "" {
+		temp, ok := values[c.idVariable]
+		if !ok {
+			return false, errors.Errorf("Variable %s not found", c.idVariable)
+		}
+		id := fmt.Sprintf("%v", temp)
+		if c.lastID != "" && id != c.lastID {
+			rebooted = true
+		}
+		c.lastID = id
+	}
+
+	return rebooted, nil
+}
+
+// pin return the pin state. Lock must be held
+func (c *Client) pin(pin int) *pinState {
+	state, ok := c.pins[pin]
+	if !ok {
+		state = &pinState{}
+		c.pins[pin] = state
+	}
+
+	return state
+}
+
+// sortedPins return the configured pins ordered. Lock must be held
+func (c *Client) sortedPins() []int {
+	pins := make([]int, 0, len(c.pins))
+	for pin := range c.pins {
+		pins = append(pins, pin)
+	}
+	sort.Ints(pins)
+
+	return pins
+}
+
+func copyMode(mode arest.Mode) arest.Mode {
+	desired := arest.NewMode()
+	switch mode.String() {
+	case "input":
+		desired.SetModeInput()
+	case "input_pullup":
+		desired.SetModeInputPullup()
+	case "output":
+		desired.SetModeOutput()
+	}
+
+	return desired
+}
+
+func copyLevel(level arest.Level) arest.Level {
+	desired := arest.NewLevel()
+	if level.IsHigh() {
+		desired.SetLevelHigh()
+	} else {
+		desired.SetLevelLow()
+	}
+
+	return desired
+}
@@ -0,0 +1,140 @@
+package reconciler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/disaster37/go-arest"
+	"github.com/disaster37/go-arest/rest"
+	"github.com/jarcoal/httpmock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	prefixed "github.com/x-cray/logrus-prefixed-formatter"
+)
+
+func TestReconciler(t *testing.T) {
+	// Init logger
+	logrus.SetFormatter(new(prefixed.TextFormatter))
+	logrus.SetLevel(logrus.DebugLevel)
+
+	uptime := 10
+	boardID := "002"
+	pinLevel := 1
+	writes := make([]string, 0)
+	recorder := func(req *http.Request) (*http.Response, error) {
+		writes = append(writes, req.URL.Path)
+		return httpmock.NewStringResponse(200, `{}`), nil
+	}
+	httpmock.RegisterResponder("POST", "http://localhost/mode/0/o", recorder)
+	httpmock.RegisterResponder("POST", "http://localhost/digital/0/1", recorder)
+	httpmock.RegisterResponder("POST", "http://localhost/mode/1/i", recorder)
+	httpmock.RegisterResponder("POST", "http://localhost/analog/3/128", recorder)
+	isDown := false
+	httpmock.RegisterResponder("GET", "http://localhost/", func(req *http.Request) (*http.Response, error) {
+		if isDown {
+			return nil, http.ErrHandlerTimeout
+		}
+		return httpmock.NewJsonResponse(200, map[string]interface{}{
+			"variables": map[string]interface{}{
+				"uptime": uptime,
+				"id":     boardID,
+			},
+		})
+	})
+	httpmock.RegisterResponder("GET", "http://localhost/digital/0", func(req *http.Request) (*http.Response, error) {
+		return httpmock.NewJsonResponse(200, map[string]interface{}{
+			"return_value": pinLevel,
+		})
+	})
+
+	client := NewClient(rest.MockRestClient())
+	reconciler := client.(*Client)
+	reconciler.SetUptimeVariable("uptime")
+	reconciler.SetIDVariable("id")
+	nbReboot := 0
+	reconciler.OnReboot(func() {
+		nbReboot++
+	})
+	nbDrift := 0
+	reconciler.OnDrift(func(drift *Drift) {
+		nbDrift++
+	})
+
+	// Configure pins
+	mode := arest.NewMode()
+	mode.SetModeOutput()
+	assert.NoError(t, client.SetPinMode(0, mode))
+	level := arest.NewLevel()
+	level.SetLevelHigh()
+	assert.NoError(t, client.DigitalWrite(0, level))
+	mode = arest.NewMode()
+	mode.SetModeInput()
+	assert.NoError(t, client.SetPinMode(1, mode))
+	assert.NoError(t, client.AnalogWrite(3, 128))
+
+	// Desired state is a copy
+	level.SetLevelLow()
+
+	// No reboot and no drift
+	writes = writes[:0]
+	drifts, err := reconciler.Check()
+	assert.NoError(t, err)
+	assert.Empty(t, drifts)
+	assert.Empty(t, writes)
+	assert.Equal(t, 0, nbReboot)
+
+	// Reboot detected from uptime
+	uptime = 1
+	drifts, err = reconciler.Check()
+	assert.NoError(t, err)
+	assert.Empty(t, drifts)
+	assert.Equal(t, 1, nbReboot)
+	assert.Equal(t, []string{"/mode/0/o", "/digital/0/1", "/mode/1/i", "/analog/3/128"}, writes)
+
+	// Reboot detected from id
+	writes = writes[:0]
+	uptime = 2
+	boardID = "003"
+	_, err = reconciler.Check()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, nbReboot)
+	assert.Len(t, writes, 4)
+
+	// Application error is not connection drop
+	writes = writes[:0]
+	httpmock.RegisterResponder("GET", "http://localhost/bad", httpmock.NewJsonResponderOrPanic(200, map[string]interface{}{}))
+	_, err = client.ReadValue("bad")
+	assert.Error(t, err)
+	_, err = reconciler.Check()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, nbReboot)
+	assert.Empty(t, writes)
+
+	// Reboot detected from connection drop
+	isDown = true
+	_, err = reconciler.Check()
+	assert.Error(t, err)
+	isDown = false
+	_, err = reconciler.Check()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, nbReboot)
+	assert.Len(t, writes, 4)
+
+	// Drift
+	writes = writes[:0]
+	pinLevel = 0
+	drifts, err = reconciler.Check()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, nbReboot)
+	assert.Empty(t, writes)
+	if assert.Len(t, drifts, 1) {
+		assert.Equal(t, 0, drifts[0].Pin)
+		assert.True(t, drifts[0].Desired.IsHigh())
+		assert.True(t, drifts[0].Actual.IsLow())
+	}
+	assert.Equal(t, 1, nbDrift)
+
+	// Bad interval
+	assert.Error(t, reconciler.Run(context.Background(), 0))
+}
@@ -8,11 +8,16 @@ import (
 	"github.com/go-resty/resty/v2"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
 )
 
+const tracerName = "github.com/disaster37/go-arest/rest"
+
 // Client implement arest interface
 type Client struct {
-	resty *resty.Client
+	resty  *resty.Client
+	tracer trace.Tracer
 }
 
 // NewClient permit to initialize new client Object
@@ -23,7 +28,8 @@ func NewClient(url string) arest.Arest {
 		SetTimeout(10 * time.Second)
 
 	return &Client{
-		resty: resty,
+		resty:  resty,
+		tracer: otel.Tracer(tracerName),
 	}
 }
 
@@ -32,14 +38,23 @@ func (c *Client) Client() *resty.Client {
 	return c.resty
 }
 
+// SetTracerProvider permit to use custom OpenTelemetry tracer provider
+// By default, it use the global tracer provider
+func (c *Client) SetTracerProvider(provider trace.TracerProvider) {
+	c.tracer = provider.Tracer(tracerName)
+}
+
 // SetPinMode permit to set pin mode
 func (c *Client) SetPinMode(pin int, mode arest.Mode) (err error) {
+	var resp *resty.Response
+	_, span := arest.StartSpan(c.tracer, "SetPinMode", arest.AttributePin.Int(pin))
+	defer func() { arest.EndSpan(span, responseSize(resp), err) }()
 
 	log.Debugf("Pin: %d, Mode: %s", pin, mode.String())
 
 	url := fmt.Sprintf("/mode/%d/%s", pin, mode.Mode())
 
-	resp, err := c.resty.R().
+	resp, err = c.resty.R().
 		SetHeader("Accept", "application/json").
 		Post(url)
 
@@ -51,12 +66,15 @@ func (c *Client) SetPinMode(pin int, mode arest.Mode) (err error) {
 
 // DigitalWrite permit to set level on pin
 func (c *Client) DigitalWrite(pin int, level arest.Level) (err error) {
+	var resp *resty.Response
+	_, span := arest.StartSpan(c.tracer, "DigitalWrite", arest.AttributePin.Int(pin))
+	defer func() { arest.EndSpan(span, responseSize(resp), err) }()
 
 	log.Debugf("Pin: %d, Level: %s", pin, level.String())
 
 	url := fmt.Sprintf("/digital/%d/%d", pin, level.Level())
 
-	resp, err := c.resty.R().
+	resp, err = c.resty.R().
 		SetHeader("Accept", "application/json").
 		Post(url)
 
@@ -67,13 +85,16 @@ func (c *Client) DigitalWrite(pin int, level arest.Level) (err error) {
 
 // DigitalRead permit to read level from pin
 func (c *Client) DigitalRead(pin int) (level arest.Level, err error) {
+	var resp *resty.Response
+	_, span := arest.StartSpan(c.tracer, "DigitalRead", arest.AttributePin.Int(pin))
+	defer func() { arest.EndSpan(span, responseSize(resp), err) }()
 
 	log.Debugf("Pin: %d", pin)
 
 	url := fmt.Sprintf("/digital/%d", pin)
 	data := make(map[string]interface{})
 
-	resp, err := c.resty.R().
+	resp, err = c.resty.R().
 		SetHeader("Accept", "application/json").
 		SetResult(&data).
 		Get(url)
@@ -93,15 +114,67 @@ func (c *Client) DigitalRead(pin int) (level arest.Level, err error) {
 	return level, err
 }
 
+// AnalogRead permit to read analog value from pin
+func (c *Client) AnalogRead(pin int) (value int, err error) {
+	var resp *resty.Response
+	_, span := arest.StartSpan(c.tracer, "AnalogRead", arest.AttributePin.Int(pin))
+	defer func() { arest.EndSpan(span, responseSize(resp), err) }()
+
+	log.Debugf("Pin: %d", pin)
+
+	url := fmt.Sprintf("/analog/%d", pin)
+	data := make(map[string]interface{})
+
+	resp, err = c.resty.R().
+		SetHeader("Accept", "application/json").
+		SetResult(&data).
+		Get(url)
+	if err != nil {
+		return value, err
+	}
+
+	log.Debugf("Resp: %s", resp.String())
+
+	if temp, ok := data["return_value"]; ok {
+		value = int(temp.(float64))
+	} else {
+		err = errors.Errorf("No value found for pin %d", pin)
+	}
+
+	return value, err
+}
+
+// AnalogWrite permit to set analog value (PWM) on pin
+func (c *Client) AnalogWrite(pin int, value int) (err error) {
+	var resp *resty.Response
+	_, span := arest.StartSpan(c.tracer, "AnalogWrite", arest.AttributePin.Int(pin))
+	defer func() { arest.EndSpan(span, responseSize(resp), err) }()
+
+	log.Debugf("Pin: %d, Value: %d", pin, value)
+
+	url := fmt.Sprintf("/analog/%d/%d", pin, value)
+
+	resp, err = c.resty.R().
+		SetHeader("Accept", "application/json").
+		Post(url)
+
+	log.Debugf("Resp: %s", resp.String())
+
+	return err
+}
+
 // ReadValue permit to read user variable
 func (c *Client) ReadValue(name string) (value interface{}, err error) {
+	var resp *resty.Response
+	_, span := arest.StartSpan(c.tracer, "ReadValue", arest.AttributeName.String(name))
+	defer func() { arest.EndSpan(span, responseSize(resp), err) }()
 
 	log.Debugf("Value name: %s", name)
 
 	url := fmt.Sprintf("/%s", name)
 	data := make(map[string]interface{})
 
-	resp, err := c.resty.R().
+	resp, err = c.resty.R().
 		SetHeader("Accept", "application/json").
 		SetResult(&data).
 		Get(url)
@@ -122,10 +195,13 @@ func (c *Client) ReadValue(name string) (value interface{}, err error) {
 
 // ReadValues permit to read user variable
 func (c *Client) ReadValues() (values map[string]interface{}, err error) {
+	var resp *resty.Response
+	_, span := arest.StartSpan(c.tracer, "ReadValues")
+	defer func() { arest.EndSpan(span, responseSize(resp), err) }()
 
 	data := make(map[string]interface{})
 
-	resp, err := c.resty.R().
+	resp, err = c.resty.R().
 		SetHeader("Accept", "application/json").
 		SetResult(&data).
 		Get("/")
@@ -146,6 +222,9 @@ func (c *Client) ReadValues() (values map[string]interface{}, err error) {
 
 // CallFunction permit to call user function
 func (c *Client) CallFunction(name string, param string) (value int, err error) {
+	var resp *resty.Response
+	_, span := arest.StartSpan(c.tracer, "CallFunction", arest.AttributeName.String(name))
+	defer func() { arest.EndSpan(span, responseSize(resp), err) }()
 
 	log.Debugf("Function: %s, param: %s", name, param)
 
@@ -153,7 +232,7 @@ func (c *Client) CallFunction(name string, param string) (value int, err error)
 
 	data := make(map[string]interface{})
 
-	resp, err := c.resty.R().
+	resp, err = c.resty.R().
 		SetQueryParams(map[string]string{
 			"params": param,
 		}).
@@ -175,3 +254,11 @@ func (c *Client) CallFunction(name string, param string) (value int, err error)
 	return value, err
 
 }
+
+func responseSize(resp *resty.Response) int {
+	if resp == nil {
+		return 0
+	}
+
+	return int(resp.Size())
+}
@@ -1,6 +1,7 @@
 package rest
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/disaster37/go-arest"
@@ -9,6 +10,10 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 	prefixed "github.com/x-cray/logrus-prefixed-formatter"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type ArestTestSuite struct {
@@ -81,6 +86,32 @@ func (s *ArestTestSuite) TestDigitalRead() {
 	assert.Equal(s.T(), "high", level.String())
 }
 
+func (s *ArestTestSuite) TestAnalogRead() {
+
+	//fixture := `{"return_value": 512, "id": "002", "name": "TFP", "hardware": "arduino", "connected": true}`
+	fixture := map[string]interface{}{
+		"return_value": 512,
+	}
+	responder := httpmock.NewJsonResponderOrPanic(200, fixture)
+	fakeURL := "http://localhost/analog/0"
+	httpmock.RegisterResponder("GET", fakeURL, responder)
+
+	value, err := s.client.AnalogRead(0)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), 512, value)
+}
+
+func (s *ArestTestSuite) TestAnalogWrite() {
+
+	fixture := `{"message": "Pin D3 set to 128", "id": "002", "name": "TFP", "hardware": "arduino", "connected": true}`
+	responder := httpmock.NewStringResponder(200, fixture)
+	fakeURL := "http://localhost/analog/3/128"
+	httpmock.RegisterResponder("POST", fakeURL, responder)
+
+	err := s.client.AnalogWrite(3, 128)
+	assert.NoError(s.T(), err)
+}
+
 func (s *ArestTestSuite) TestReadValue() {
 
 	//fixture := `{"isRebooted": true, "id": "002", "name": "TFP", "hardware": "arduino", "connected": true}`
@@ -137,3 +168,41 @@ func (s *ArestTestSuite) TestCallFunction() {
 	resp, err = s.client.CallFunction("bad", "test")
 	assert.Error(s.T(), err)
 }
+
+func (s *ArestTestSuite) TestTracing() {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	client := s.client.(*Client)
+	client.SetTracerProvider(provider)
+	defer client.SetTracerProvider(trace.NewNoopTracerProvider())
+
+	fixture := map[string]interface{}{
+		"return_value": 1,
+	}
+	body, _ := json.Marshal(fixture)
+	responder := httpmock.NewJsonResponderOrPanic(200, fixture)
+	httpmock.RegisterResponder("GET", "http://localhost/digital/2", responder)
+
+	_, err := s.client.DigitalRead(2)
+	assert.NoError(s.T(), err)
+
+	spans := exporter.GetSpans()
+	if assert.Len(s.T(), spans, 1) {
+		assert.Equal(s.T(), "arest.DigitalRead", spans[0].Name)
+		assert.Equal(s.T(), trace.SpanKindClient, spans[0].SpanKind)
+		assert.Contains(s.T(), spans[0].Attributes, arest.AttributeOperation.String("DigitalRead"))
+		assert.Contains(s.T(), spans[0].Attributes, arest.AttributePin.Int(2))
+		assert.Contains(s.T(), spans[0].Attributes, arest.AttributeResponseSize.Int(len(body)))
+	}
+
+	// Error is recorded
+	exporter.Reset()
+	_, err = s.client.ReadValue("bad")
+	assert.Error(s.T(), err)
+	spans = exporter.GetSpans()
+	if assert.Len(s.T(), spans, 1) {
+		assert.Equal(s.T(), "arest.ReadValue", spans[0].Name)
+		assert.Contains(s.T(), spans[0].Attributes, arest.AttributeName.String("bad"))
+		assert.Equal(s.T(), codes.Error, spans[0].Status.Code)
+	}
+}
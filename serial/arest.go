@@ -1,6 +1,7 @@
 package serial
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -9,14 +10,19 @@ import (
 	"github.com/disaster37/go-arest"
 	"github.com/pkg/errors"
 	"go.bug.st/serial"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
 )
 
+const tracerName = "github.com/disaster37/go-arest/serial"
+
 // Client implement arest interface
 type Client struct {
 	serialPort serial.Port
 	sem        chan int
 	timeout    time.Duration
 	url        string
+	tracer     trace.Tracer
 }
 
 // NewClient permit to initialize new client Object
@@ -36,6 +42,7 @@ func NewClient(url string, timeout time.Duration, debug bool) (arest.Arest, erro
 		sem:        make(chan int, 1),
 		timeout:    timeout,
 		url:        url,
+		tracer:     otel.Tracer(tracerName),
 	}
 
 	return client, nil
@@ -46,9 +53,19 @@ func (c *Client) Client() serial.Port {
 	return c.serialPort
 }
 
+// SetTracerProvider permit to use custom OpenTelemetry tracer provider
+// By default, it use the global tracer provider
+func (c *Client) SetTracerProvider(provider trace.TracerProvider) {
+	c.tracer = provider.Tracer(tracerName)
+}
+
 // SetPinMode permit to set pin mode
 func (c *Client) SetPinMode(pin int, mode arest.Mode) (err error) {
-	c.takeSemaphore()
+	var resp string
+	ctx, span := arest.StartSpan(c.tracer, "SetPinMode", arest.AttributePin.Int(pin))
+	defer func() { arest.EndSpan(span, len(resp), err) }()
+
+	c.takeSemaphore(ctx)
 	defer c.releazeSemaphore()
 	arest.Debug("Pin: %d, Mode: %s", pin, mode.String())
 
@@ -59,7 +76,7 @@ func (c *Client) SetPinMode(pin int, mode arest.Mode) (err error) {
 		return err
 	}
 
-	resp, err := c.read()
+	resp, err = c.read()
 	if err != nil {
 		return err
 	}
@@ -72,7 +89,11 @@ func (c *Client) SetPinMode(pin int, mode arest.Mode) (err error) {
 
 // DigitalWrite permit to set level on pin
 func (c *Client) DigitalWrite(pin int, level arest.Level) (err error) {
-	c.takeSemaphore()
+	var resp string
+	ctx, span := arest.StartSpan(c.tracer, "DigitalWrite", arest.AttributePin.Int(pin))
+	defer func() { arest.EndSpan(span, len(resp), err) }()
+
+	c.takeSemaphore(ctx)
 	defer c.releazeSemaphore()
 	arest.Debug("Pin: %d, Level: %s", pin, level.String())
 
@@ -83,7 +104,7 @@ func (c *Client) DigitalWrite(pin int, level arest.Level) (err error) {
 		return err
 	}
 
-	resp, err := c.read()
+	resp, err = c.read()
 	if err != nil {
 		return err
 	}
@@ -95,7 +116,11 @@ func (c *Client) DigitalWrite(pin int, level arest.Level) (err error) {
 
 // DigitalRead permit to read level from pin
 func (c *Client) DigitalRead(pin int) (level arest.Level, err error) {
-	c.takeSemaphore()
+	var resp string
+	ctx, span := arest.StartSpan(c.tracer, "DigitalRead", arest.AttributePin.Int(pin))
+	defer func() { arest.EndSpan(span, len(resp), err) }()
+
+	c.takeSemaphore(ctx)
 	defer c.releazeSemaphore()
 	arest.Debug("Pin: %d", pin)
 
@@ -107,7 +132,7 @@ func (c *Client) DigitalRead(pin int) (level arest.Level, err error) {
 		return nil, err
 	}
 
-	resp, err := c.read()
+	resp, err = c.read()
 	if err != nil {
 		return nil, err
 	}
@@ -129,9 +154,79 @@ func (c *Client) DigitalRead(pin int) (level arest.Level, err error) {
 	return level, err
 }
 
+// AnalogRead permit to read analog value from pin
+func (c *Client) AnalogRead(pin int) (value int, err error) {
+	var resp string
+	ctx, span := arest.StartSpan(c.tracer, "AnalogRead", arest.AttributePin.Int(pin))
+	defer func() { arest.EndSpan(span, len(resp), err) }()
+
+	c.takeSemaphore(ctx)
+	defer c.releazeSemaphore()
+	arest.Debug("Pin: %d", pin)
+
+	url := fmt.Sprintf("/analog/%d\n\r", pin)
+	data := make(map[string]interface{})
+
+	_, err = c.serialPort.Write([]byte(url))
+	if err != nil {
+		return value, err
+	}
+
+	resp, err = c.read()
+	if err != nil {
+		return value, err
+	}
+
+	arest.Debug("Resp: %s", resp)
+
+	err = json.Unmarshal([]byte(resp), &data)
+	if err != nil {
+		return value, err
+	}
+
+	if temp, ok := data["return_value"]; ok {
+		value = int(temp.(float64))
+	} else {
+		err = errors.Errorf("No value found for pin %d", pin)
+	}
+
+	return value, err
+}
+
+// AnalogWrite permit to set analog value (PWM) on pin
+func (c *Client) AnalogWrite(pin int, value int) (err error) {
+	var resp string
+	ctx, span := arest.StartSpan(c.tracer, "AnalogWrite", arest.AttributePin.Int(pin))
+	defer func() { arest.EndSpan(span, len(resp), err) }()
+
+	c.takeSemaphore(ctx)
+	defer c.releazeSemaphore()
+	arest.Debug("Pin: %d, Value: %d", pin, value)
+
+	url := fmt.Sprintf("/analog/%d/%d\n\r", pin, value)
+
+	_, err = c.serialPort.Write([]byte(url))
+	if err != nil {
+		return err
+	}
+
+	resp, err = c.read()
+	if err != nil {
+		return err
+	}
+
+	arest.Debug("Resp: %s", resp)
+
+	return nil
+}
+
 // ReadValue permit to read user variable
 func (c *Client) ReadValue(name string) (value interface{}, err error) {
-	c.takeSemaphore()
+	var resp string
+	ctx, span := arest.StartSpan(c.tracer, "ReadValue", arest.AttributeName.String(name))
+	defer func() { arest.EndSpan(span, len(resp), err) }()
+
+	c.takeSemaphore(ctx)
 	defer c.releazeSemaphore()
 	arest.Debug("Value name: %s", name)
 
@@ -143,7 +238,7 @@ func (c *Client) ReadValue(name string) (value interface{}, err error) {
 		return nil, err
 	}
 
-	resp, err := c.read()
+	resp, err = c.read()
 	if err != nil {
 		return nil, err
 	}
@@ -166,7 +261,11 @@ func (c *Client) ReadValue(name string) (value interface{}, err error) {
 
 // ReadValues permit to read user variable
 func (c *Client) ReadValues() (values map[string]interface{}, err error) {
-	c.takeSemaphore()
+	var resp string
+	ctx, span := arest.StartSpan(c.tracer, "ReadValues")
+	defer func() { arest.EndSpan(span, len(resp), err) }()
+
+	c.takeSemaphore(ctx)
 	defer c.releazeSemaphore()
 	url := "/\n\r"
 	data := make(map[string]interface{})
@@ -176,7 +275,7 @@ func (c *Client) ReadValues() (values map[string]interface{}, err error) {
 		return nil, err
 	}
 
-	resp, err := c.read()
+	resp, err = c.read()
 	if err != nil {
 		return nil, err
 	}
@@ -199,7 +298,11 @@ func (c *Client) ReadValues() (values map[string]interface{}, err error) {
 
 // CallFunction permit to call user function
 func (c *Client) CallFunction(name string, param string) (value int, err error) {
-	c.takeSemaphore()
+	var resp string
+	ctx, span := arest.StartSpan(c.tracer, "CallFunction", arest.AttributeName.String(name))
+	defer func() { arest.EndSpan(span, len(resp), err) }()
+
+	c.takeSemaphore(ctx)
 	defer c.releazeSemaphore()
 	arest.Debug("Function: %s, param: %s", name, param)
 
@@ -211,7 +314,7 @@ func (c *Client) CallFunction(name string, param string) (value int, err error)
 		return value, err
 	}
 
-	resp, err := c.read()
+	resp, err = c.read()
 	if err != nil {
 		return value, err
 	}
@@ -260,7 +363,14 @@ func (c *Client) read() (string, error) {
 	return resp.String(), nil
 }
 
-func (c *Client) takeSemaphore() {
+func (c *Client) takeSemaphore(ctx context.Context) {
+	_, span := c.tracer.Start(ctx, "arest.serial.lock")
+	c.lock()
+	span.End()
+}
+
+// lock take the semaphore without span, for call outside of aREST operation
+func (c *Client) lock() {
 	c.sem <- 1
 }
 
@@ -276,7 +386,7 @@ func (c *Client) watchdog(finished *bool) {
 		go func() {
 
 			isConnected := false
-			c.takeSemaphore()
+			c.lock()
 			defer c.releazeSemaphore()
 
 			for !isConnected {
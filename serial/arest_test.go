@@ -0,0 +1,140 @@
+package serial
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/disaster37/go-arest"
+	"github.com/stretchr/testify/assert"
+	"go.bug.st/serial"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// fakePort answer the same response to each request
+type fakePort struct {
+	response string
+	pending  bytes.Buffer
+	requests []string
+	mutex    sync.Mutex
+}
+
+func (p *fakePort) SetMode(mode *serial.Mode) error {
+	return nil
+}
+
+func (p *fakePort) Read(b []byte) (int, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.pending.Read(b)
+}
+
+func (p *fakePort) Write(b []byte) (int, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.requests = append(p.requests, string(b))
+	p.pending.WriteString(p.response + "\n")
+
+	return len(b), nil
+}
+
+func (p *fakePort) ResetInputBuffer() error {
+	return nil
+}
+
+func (p *fakePort) ResetOutputBuffer() error {
+	return nil
+}
+
+func (p *fakePort) SetDTR(dtr bool) error {
+	return nil
+}
+
+func (p *fakePort) SetRTS(rts bool) error {
+	return nil
+}
+
+func (p *fakePort) GetModemStatusBits() (*serial.ModemStatusBits, error) {
+	return &serial.ModemStatusBits{}, nil
+}
+
+func (p *fakePort) Close() error {
+	return nil
+}
+
+// newFakeClient return client that use fake port
+func newFakeClient(port *fakePort) *Client {
+	return &Client{
+		serialPort: port,
+		sem:        make(chan int, 1),
+		timeout:    1 * time.Minute,
+		tracer:     otel.Tracer(tracerName),
+	}
+}
+
+func TestAnalogRead(t *testing.T) {
+	port := &fakePort{response: `{"return_value": 512}`}
+	client := newFakeClient(port)
+
+	value, err := client.AnalogRead(1)
+	assert.NoError(t, err)
+	assert.Equal(t, 512, value)
+	assert.Equal(t, []string{"/analog/1\n\r"}, port.requests)
+
+	// No value
+	port = &fakePort{response: `{}`}
+	client = newFakeClient(port)
+	_, err = client.AnalogRead(1)
+	assert.Error(t, err)
+}
+
+func TestAnalogWrite(t *testing.T) {
+	port := &fakePort{response: `{"message": "Pin D3 set to 128"}`}
+	client := newFakeClient(port)
+
+	err := client.AnalogWrite(3, 128)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/analog/3/128\n\r"}, port.requests)
+}
+
+func TestTracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	port := &fakePort{response: `{"return_value": 1}`}
+	client := &Client{
+		serialPort: port,
+		sem:        make(chan int, 1),
+		timeout:    1 * time.Minute,
+	}
+	client.SetTracerProvider(provider)
+
+	level, err := client.DigitalRead(2)
+	assert.NoError(t, err)
+	assert.True(t, level.IsHigh())
+	assert.Equal(t, []string{"/digital/2\n\r"}, port.requests)
+
+	// Lock span is child of the operation span
+	spans := exporter.GetSpans()
+	if assert.Len(t, spans, 2) {
+		lock := spans[0]
+		operation := spans[1]
+		assert.Equal(t, "arest.serial.lock", lock.Name)
+		assert.Equal(t, "arest.DigitalRead", operation.Name)
+		assert.Equal(t, trace.SpanKindClient, operation.SpanKind)
+		assert.Equal(t, operation.SpanContext.SpanID(), lock.Parent.SpanID())
+		assert.Contains(t, operation.Attributes, arest.AttributePin.Int(2))
+		assert.Contains(t, operation.Attributes, arest.AttributeResponseSize.Int(len(port.response)+1))
+	}
+
+	// Lock outside of operation, like watchdog reconnection, has no span
+	exporter.Reset()
+	client.lock()
+	client.releazeSemaphore()
+	assert.Empty(t, exporter.GetSpans())
+}
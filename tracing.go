@@ -0,0 +1,46 @@
+package arest
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Attributes set on spans created by aREST clients
+const (
+	// AttributeOperation is the aREST operation name (SetPinMode, DigitalRead, ...)
+	AttributeOperation attribute.Key = "arest.operation"
+
+	// AttributePin is the pin number
+	AttributePin attribute.Key = "arest.pin"
+
+	// AttributeName is the variable or function name
+	AttributeName attribute.Key = "arest.name"
+
+	// AttributeResponseSize is the response size in bytes
+	AttributeResponseSize attribute.Key = "arest.response.size"
+)
+
+// StartSpan start new root client span for aREST operation
+func StartSpan(tracer trace.Tracer, operation string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs, AttributeOperation.String(operation))
+
+	return tracer.Start(
+		context.Background(),
+		"arest."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// EndSpan set the response size, record error if needed and end the span
+func EndSpan(span trace.Span, responseSize int, err error) {
+	span.SetAttributes(AttributeResponseSize.Int(responseSize))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,28 @@
+package arest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestStartSpan(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	tracer := provider.Tracer("test")
+
+	ctx, span := StartSpan(tracer, "DigitalRead", AttributePin.Int(2))
+	assert.Equal(t, span.SpanContext(), trace.SpanContextFromContext(ctx))
+	span.End()
+
+	spans := exporter.GetSpans()
+	if assert.Len(t, spans, 1) {
+		assert.Equal(t, "arest.DigitalRead", spans[0].Name)
+		assert.False(t, spans[0].Parent.IsValid())
+		assert.Contains(t, spans[0].Attributes, AttributePin.Int(2))
+		assert.Contains(t, spans[0].Attributes, AttributeOperation.String("DigitalRead"))
+	}
+}
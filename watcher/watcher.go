@@ -0,0 +1,269 @@
+package watcher
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/disaster37/go-arest"
+	"github.com/disaster37/go-arest/device"
+	"github.com/pkg/errors"
+)
+
+// ErrAlreadyRun is returned when watcher is run twice
+var ErrAlreadyRun = errors.New("Watcher is already run")
+
+// Watcher poll pins and emit event when they change
+type Watcher interface {
+
+	// WatchDigital add digital pin to poll at interval
+	// Pins must be added before Run
+	WatchDigital(pin int, interval time.Duration) (err error)
+
+	// WatchAnalog add analog pin to poll at interval
+	// Event is emitted only when value move at least from threshold since the last event
+	// Pins must be added before Run
+	WatchAnalog(pin int, interval time.Duration, threshold int) (err error)
+
+	// OnEvent add handler called on each event
+	// Handlers can be called concurrently when several pins change
+	OnEvent(handler func(event Event))
+
+	// Events return the channel where events are sent
+	// It's closed when Run return
+	Events() <-chan Event
+
+	// Run poll pins until context is done
+	// It can be run only once, next calls return ErrAlreadyRun
+	Run(ctx context.Context) (err error)
+}
+
+// Event is the pin change event
+type Event interface {
+
+	// Pin return the pin number
+	Pin() int
+
+	// Time return when the change is detected
+	Time() time.Time
+}
+
+// DigitalEvent is emitted when digital pin level change
+type DigitalEvent struct {
+	pin   int
+	time  time.Time
+	level arest.Level
+}
+
+// AnalogEvent is emitted when analog pin value cross the threshold
+type AnalogEvent struct {
+	pin           int
+	time          time.Time
+	value         int
+	previousValue int
+}
+
+// WatcherImp implement the watcher interface
+type WatcherImp struct {
+	client  arest.Arest
+	pins    []*watchedPin
+	emitter *device.Emitter[Event]
+	isRun   bool
+	mutex   sync.Mutex
+}
+
+type watchedPin struct {
+	pin       int
+	analog    bool
+	interval  time.Duration
+	threshold int
+}
+
+// NewWatcher return new watcher object
+func NewWatcher(client arest.Arest) Watcher {
+	return &WatcherImp{
+		client:  client,
+		pins:    make([]*watchedPin, 0),
+		emitter: device.NewEmitter[Event](),
+	}
+}
+
+// WatchDigital add digital pin to poll at interval
+// Pins must be added before Run
+func (h *WatcherImp) WatchDigital(pin int, interval time.Duration) (err error) {
+	return h.watch(&watchedPin{
+		pin:      pin,
+		interval: interval,
+	})
+}
+
+// WatchAnalog add analog pin to poll at interval
+// Event is emitted only when value move at least from threshold since the last event
+// Pins must be added before Run
+func (h *WatcherImp) WatchAnalog(pin int, interval time.Duration, threshold int) (err error) {
+	return h.watch(&watchedPin{
+		pin:       pin,
+		analog:    true,
+		interval:  interval,
+		threshold: threshold,
+	})
+}
+
+// watch add pin to poll
+func (h *WatcherImp) watch(p *watchedPin) (err error) {
+	if p.interval <= 0 {
+		return errors.Errorf("Interval of pin %d must be positive: %s", p.pin, p.interval)
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.isRun {
+		return errors.Wrapf(ErrAlreadyRun, "Can't watch pin %d", p.pin)
+	}
+	h.pins = append(h.pins, p)
+
+	return nil
+}
+
+// OnEvent add handler called on each event
+// Handlers can be called concurrently when several pins change
+func (h *WatcherImp) OnEvent(handler func(event Event)) {
+	h.emitter.OnEvent(handler)
+}
+
+// Events return the channel where events are sent
+// It's closed when Run return
+func (h *WatcherImp) Events() <-chan Event {
+	return h.emitter.Events()
+}
+
+// Run poll pins until context is done
+// It can be run only once, next calls return ErrAlreadyRun
+func (h *WatcherImp) Run(ctx context.Context) (err error) {
+	h.mutex.Lock()
+	if h.isRun {
+		h.mutex.Unlock()
+		return ErrAlreadyRun
+	}
+	h.isRun = true
+	pins := h.pins
+	h.mutex.Unlock()
+
+	wg := &sync.WaitGroup{}
+
+	for _, p := range pins {
+		wg.Add(1)
+		go func(p *watchedPin) {
+			defer wg.Done()
+			// Interval is checked when pin is added
+			if p.analog {
+				h.pollAnalog(ctx, p)
+			} else {
+				h.pollDigital(ctx, p)
+			}
+		}(p)
+	}
+
+	wg.Wait()
+
+	h.emitter.Close()
+
+	return nil
+}
+
+// pollDigital emit event when digital pin level change
+func (h *WatcherImp) pollDigital(ctx context.Context, p *watchedPin) error {
+	var lastLevel arest.Level
+
+	return device.NewPoller().Run(ctx, p.interval, func() error {
+		level, err := h.client.DigitalRead(p.pin)
+		if err != nil {
+			return errors.Wrapf(err, "Error when read digital pin %d", p.pin)
+		}
+
+		// The first read is the reference
+		if lastLevel != nil && lastLevel.Level() != level.Level() {
+			h.emitter.Emit(ctx, &DigitalEvent{
+				pin:   p.pin,
+				time:  time.Now(),
+				level: level,
+			})
+		}
+		lastLevel = level
+
+		return nil
+	})
+}
+
+// pollAnalog emit event when analog pin value cross the threshold
+func (h *WatcherImp) pollAnalog(ctx context.Context, p *watchedPin) error {
+	var lastValue int
+	isFirst := true
+
+	return device.NewPoller().Run(ctx, p.interval, func() error {
+		value, err := h.client.AnalogRead(p.pin)
+		if err != nil {
+			return errors.Wrapf(err, "Error when read analog pin %d", p.pin)
+		}
+
+		if isFirst {
+			// The first read is the reference
+			lastValue = value
+			isFirst = false
+		} else if value != lastValue && abs(value-lastValue) >= p.threshold {
+			h.emitter.Emit(ctx, &AnalogEvent{
+				pin:           p.pin,
+				time:          time.Now(),
+				value:         value,
+				previousValue: lastValue,
+			})
+			lastValue = value
+		}
+
+		return nil
+	})
+}
+
+// Pin return the pin number
+func (e *DigitalEvent) Pin() int {
+	return e.pin
+}
+
+// Time return when the change is detected
+func (e *DigitalEvent) Time() time.Time {
+	return e.time
+}
+
+// Level return the new pin level
+func (e *DigitalEvent) Level() arest.Level {
+	return e.level
+}
+
+// Pin return the pin number
+func (e *AnalogEvent) Pin() int {
+	return e.pin
+}
+
+// Time return when the change is detected
+func (e *AnalogEvent) Time() time.Time {
+	return e.time
+}
+
+// Value return the new pin value
+func (e *AnalogEvent) Value() int {
+	return e.value
+}
+
+// PreviousValue return the pin value of the last event
+func (e *AnalogEvent) PreviousValue() int {
+	return e.previousValue
+}
+
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+
+	return value
+}
@@ -0,0 +1,118 @@
+package watcher
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/disaster37/go-arest/rest"
+	"github.com/jarcoal/httpmock"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	prefixed "github.com/x-cray/logrus-prefixed-formatter"
+)
+
+func TestWatcher(t *testing.T) {
+	// Init logger
+	logrus.SetFormatter(new(prefixed.TextFormatter))
+	logrus.SetLevel(logrus.DebugLevel)
+
+	var digitalValue, analogValue int32
+	httpmock.RegisterResponder("GET", "http://localhost/digital/0", func(req *http.Request) (*http.Response, error) {
+		return httpmock.NewJsonResponse(200, map[string]interface{}{
+			"return_value": atomic.LoadInt32(&digitalValue),
+		})
+	})
+	httpmock.RegisterResponder("GET", "http://localhost/analog/1", func(req *http.Request) (*http.Response, error) {
+		return httpmock.NewJsonResponse(200, map[string]interface{}{
+			"return_value": atomic.LoadInt32(&analogValue),
+		})
+	})
+
+	client := rest.MockRestClient()
+	watcher := NewWatcher(client)
+	assert.Error(t, watcher.WatchDigital(0, 0))
+	assert.Error(t, watcher.WatchAnalog(1, -1*time.Millisecond, 10))
+	assert.NoError(t, watcher.WatchDigital(0, 10*time.Millisecond))
+	assert.NoError(t, watcher.WatchAnalog(1, 10*time.Millisecond, 10))
+
+	handled := make([]Event, 0)
+	mutex := &sync.Mutex{}
+	watcher.OnEvent(func(event Event) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		handled = append(handled, event)
+	})
+	// Handler can use the watcher
+	watcher.OnEvent(func(event Event) {
+		watcher.Events()
+	})
+	events := watcher.Events()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		watcher.Run(ctx)
+		close(done)
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	// Already run
+	assert.Equal(t, ErrAlreadyRun, watcher.Run(ctx))
+	assert.Equal(t, ErrAlreadyRun, errors.Cause(watcher.WatchDigital(2, 10*time.Millisecond)))
+
+	// No event without change
+	assert.Len(t, events, 0)
+
+	// Digital change
+	atomic.StoreInt32(&digitalValue, 1)
+	select {
+	case event := <-events:
+		assert.Equal(t, 0, event.Pin())
+		assert.False(t, event.Time().IsZero())
+		if assert.IsType(t, &DigitalEvent{}, event) {
+			assert.True(t, event.(*DigitalEvent).Level().IsHigh())
+		}
+	case <-time.After(1 * time.Second):
+		assert.Fail(t, "No digital event")
+	}
+
+	// Analog change under threshold
+	atomic.StoreInt32(&analogValue, 5)
+	time.Sleep(50 * time.Millisecond)
+	assert.Len(t, events, 0)
+
+	// Analog change over threshold
+	atomic.StoreInt32(&analogValue, 20)
+	select {
+	case event := <-events:
+		assert.Equal(t, 1, event.Pin())
+		if assert.IsType(t, &AnalogEvent{}, event) {
+			assert.Equal(t, 20, event.(*AnalogEvent).Value())
+			assert.Equal(t, 0, event.(*AnalogEvent).PreviousValue())
+		}
+	case <-time.After(1 * time.Second):
+		assert.Fail(t, "No analog event")
+	}
+
+	// Stop with context
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(1 * time.Second):
+		assert.Fail(t, "Watcher not stopped")
+	}
+	_, ok := <-events
+	assert.False(t, ok)
+	_, ok = <-watcher.Events()
+	assert.False(t, ok)
+	assert.Equal(t, ErrAlreadyRun, watcher.Run(ctx))
+
+	mutex.Lock()
+	assert.Len(t, handled, 2)
+	mutex.Unlock()
+}